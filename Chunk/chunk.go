@@ -0,0 +1,64 @@
+package Chunk
+
+import (
+	"strconv"
+)
+
+/**
+	Split large objects into fixed-size chunks which are cached as independent entries.
+	The chunk of index N of object "id" is cached as "id#chunkN". Every chunk is chunkSize bytes, except
+	the last one which holds the rest of the object.
+ */
+
+type Chunk struct {
+	Id			string		// id#chunkN
+	Size		int64		// size of the whole chunk
+	Start		int64		// first requested byte, relative to the chunk
+	End			int64		// last requested byte, relative to the chunk (inclusive)
+}
+
+/**
+	Number of requested bytes in this chunk.
+ */
+func (chunk Chunk) Bytes() int64 {
+	return chunk.End - chunk.Start + 1
+}
+
+func ChunkId(id string, index int64) string {
+	return id + "#chunk" + strconv.FormatInt(index, 10)
+}
+
+/**
+	Return the chunks of an object which cover the byte range [start, end] (inclusive).
+	The range is clipped to the object. A whole-object request is start = 0, end = objectSize - 1.
+ */
+func Split(id string, objectSize int64, chunkSize int64, start int64, end int64) []Chunk {
+	chunks := make([]Chunk, 0)
+	if start < 0 {
+		start = 0
+	}
+	if end >= objectSize {
+		end = objectSize - 1
+	}
+	if chunkSize <= 0 || start > end {
+		return chunks
+	}
+
+	for index := start / chunkSize; index <= end / chunkSize; index++ {
+		chunkStart := index * chunkSize
+		size := chunkSize
+		if chunkStart + size > objectSize {
+			size = objectSize - chunkStart
+		}
+		first := int64(0)
+		if start > chunkStart {
+			first = start - chunkStart
+		}
+		last := size - 1
+		if end < chunkStart + size - 1 {
+			last = end - chunkStart
+		}
+		chunks = append(chunks, Chunk{ChunkId(id, index), size, first, last})
+	}
+	return chunks
+}
@@ -0,0 +1,33 @@
+package Chunk
+
+import "testing"
+
+func TestSplitWholeObject(t *testing.T) {
+	chunks := Split("a", 250, 100, 0, 249)
+	expected := []Chunk{{"a#chunk0", 100, 0, 99}, {"a#chunk1", 100, 0, 99}, {"a#chunk2", 50, 0, 49}}
+	if len(chunks) != len(expected) {
+		t.Fatalf("%d chunks, expected %d", len(chunks), len(expected))
+	}
+	for index := range chunks {
+		if chunks[index] != expected[index] {
+			t.Fatalf("chunk %d is %v, expected %v", index, chunks[index], expected[index])
+		}
+	}
+}
+
+func TestSplitRange(t *testing.T) {
+	// the range is clipped to the object
+	chunks := Split("a", 250, 100, 150, 1000)
+	if len(chunks) != 2 || chunks[0] != (Chunk{"a#chunk1", 100, 50, 99}) || chunks[1] != (Chunk{"a#chunk2", 50, 0, 49}) {
+		t.Fatalf("chunks of bytes [150, 1000]: %v", chunks)
+	}
+	if chunks[0].Bytes() + chunks[1].Bytes() != 100 {
+		t.Fatalf("chunks cover %d bytes, expected 100", chunks[0].Bytes() + chunks[1].Bytes())
+	}
+	if chunks := Split("a", 250, 100, 120, 130); len(chunks) != 1 || chunks[0] != (Chunk{"a#chunk1", 100, 20, 30}) {
+		t.Fatalf("chunks of bytes [120, 130]: %v", chunks)
+	}
+	if len(Split("a", 250, 100, 300, 400)) != 0 || len(Split("a", 250, 0, 0, 249)) != 0 {
+		t.Fatalf("empty range or chunk size split into chunks")
+	}
+}
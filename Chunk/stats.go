@@ -0,0 +1,79 @@
+package Chunk
+
+import (
+	"fmt"
+)
+
+/**
+	Partial-hit accounting for range requests. Any simulator can be chunked with Serve as long as it has
+	a request function returning whether the object was a hit, e.g. LRU.SieveRequest, RIPQRequest,
+	or LogStructured.NewRequest:
+
+		var stats Chunk.Stats
+		stats.Serve(func(id string, size int64) bool {
+			return LRU.SieveRequest(id, strconv.FormatInt(size, 10))
+		}, record.Id, record.Size, chunkSize, record.Start, record.End)
+ */
+type Stats struct {
+	Requests		int64
+	Hits			int64		// every requested byte is served from cache
+	PartialHits		int64		// some requested bytes are served from cache
+	Chunks			int64		// number of chunks requested
+	ChunkHits		int64
+	RequestedBytes	int64
+	ServedBytes		int64		// requested bytes served from cache
+	OriginBytes		int64		// bytes fetched from origin, whole chunks or objects
+}
+
+/**
+	Request the chunks covering bytes [start, end] of the object. Return true if all of them are hits.
+ */
+func (stats *Stats) Serve(request func(id string, size int64) bool, id string, objectSize int64,
+	chunkSize int64, start int64, end int64) bool {
+	stats.Requests++
+	stats.RequestedBytes += RangeBytes(objectSize, start, end)
+
+	chunks := Split(id, objectSize, chunkSize, start, end)
+	hitChunks := 0
+	for _, chunk := range chunks {
+		stats.Chunks++
+		if request(chunk.Id, chunk.Size) {
+			stats.ChunkHits++
+			stats.ServedBytes += chunk.Bytes()
+			hitChunks++
+		} else {
+			stats.OriginBytes += chunk.Size
+		}
+	}
+
+	if hitChunks == len(chunks) {
+		stats.Hits++
+		return true
+	}
+	if hitChunks > 0 {
+		stats.PartialHits++
+	}
+	return false
+}
+
+/**
+	Record a range request served without chunking. On a miss, the whole object is fetched from origin.
+ */
+func (stats *Stats) Record(hit bool, objectSize int64, start int64, end int64) {
+	bytes := RangeBytes(objectSize, start, end)
+	stats.Requests++
+	stats.RequestedBytes += bytes
+	if hit {
+		stats.Hits++
+		stats.ServedBytes += bytes
+	} else {
+		stats.OriginBytes += objectSize
+	}
+}
+
+func (stats *Stats) String() string {
+	return fmt.Sprintf("requests: %d, hits: %d, partial hits: %d, chunks: %d, chunk hits: %d, "+
+		"requested bytes: %d, served bytes: %d, origin bytes: %d",
+		stats.Requests, stats.Hits, stats.PartialHits, stats.Chunks, stats.ChunkHits,
+		stats.RequestedBytes, stats.ServedBytes, stats.OriginBytes)
+}
@@ -0,0 +1,34 @@
+package Chunk
+
+import "testing"
+
+func TestServePartialHit(t *testing.T) {
+	var stats Stats
+	cached := map[string]bool{"a#chunk1": true}
+	request := func(id string, size int64) bool {
+		return cached[id]
+	}
+	// bytes [50, 149] cover the second half of chunk 0 and the first half of chunk 1
+	if stats.Serve(request, "a", 250, 100, 50, 149) {
+		t.Fatalf("partial hit counted as a hit")
+	}
+	if stats.PartialHits != 1 || stats.ServedBytes != 50 || stats.OriginBytes != 100 || stats.RequestedBytes != 100 {
+		t.Fatalf("after a partial hit: %s", stats.String())
+	}
+	if !stats.Serve(request, "a", 250, 100, 100, 199) {
+		t.Fatalf("range inside a cached chunk is not a hit")
+	}
+	if stats.Requests != 2 || stats.Hits != 1 || stats.Chunks != 3 || stats.ChunkHits != 2 || stats.ServedBytes != 150 {
+		t.Fatalf("after a hit: %s", stats.String())
+	}
+}
+
+func TestRecord(t *testing.T) {
+	var stats Stats
+	stats.Record(true, 250, 0, 99)
+	stats.Record(false, 250, 0, 99)
+	// a miss fetches the whole object
+	if stats.Requests != 2 || stats.Hits != 1 || stats.ServedBytes != 100 || stats.OriginBytes != 250 || stats.RequestedBytes != 200 {
+		t.Fatalf("stats: %s", stats.String())
+	}
+}
@@ -0,0 +1,71 @@
+package Chunk
+
+import (
+	"strconv"
+	"strings"
+)
+
+/**
+	One request of the trace: "timestamp id size [start end]".
+	start and end are the requested byte range (inclusive). Without them, the whole object is requested.
+ */
+type Record struct {
+	Time		int64
+	Id			string
+	Size		int64
+	Start		int64
+	End			int64
+}
+
+/**
+	Parse one line of the trace. Return false if the line is malformed.
+ */
+func ParseRecord(line string) (Record, bool) {
+	var record Record
+	tokens := strings.Fields(line)
+	if len(tokens) < 3 {
+		return record, false
+	}
+	var err error
+	record.Time, err = strconv.ParseInt(tokens[0], 10, 64)
+	if err != nil {
+		return record, false
+	}
+	record.Id = tokens[1]
+	record.Size, err = strconv.ParseInt(tokens[2], 10, 64)
+	if err != nil {
+		return record, false
+	}
+	record.Start = 0
+	record.End = record.Size - 1
+	if len(tokens) >= 5 {
+		start, errStart := strconv.ParseInt(tokens[3], 10, 64)
+		end, errEnd := strconv.ParseInt(tokens[4], 10, 64)
+		if errStart != nil || errEnd != nil {
+			return record, false
+		}
+		record.Start = start
+		record.End = end
+	}
+	return record, true
+}
+
+/**
+	Number of requested bytes, clipped to the object.
+ */
+func (record Record) Bytes() int64 {
+	return RangeBytes(record.Size, record.Start, record.End)
+}
+
+func RangeBytes(objectSize int64, start int64, end int64) int64 {
+	if start < 0 {
+		start = 0
+	}
+	if end >= objectSize {
+		end = objectSize - 1
+	}
+	if start > end {
+		return 0
+	}
+	return end - start + 1
+}
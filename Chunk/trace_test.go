@@ -0,0 +1,31 @@
+package Chunk
+
+import "testing"
+
+func TestParseRecord(t *testing.T) {
+	record, ok := ParseRecord("12 a 250")
+	if !ok || record != (Record{12, "a", 250, 0, 249}) || record.Bytes() != 250 {
+		t.Fatalf("whole-object request parsed as %v", record)
+	}
+	record, ok = ParseRecord("12 a 250 100 199")
+	if !ok || record.Start != 100 || record.End != 199 || record.Bytes() != 100 {
+		t.Fatalf("range request parsed as %v", record)
+	}
+	for _, line := range []string{"", "12 a", "x a 250", "12 a x", "12 a 250 x 199"} {
+		if _, ok := ParseRecord(line); ok {
+			t.Fatalf("malformed line %q parsed", line)
+		}
+	}
+}
+
+func TestRangeBytes(t *testing.T) {
+	for _, test := range []struct {
+		start	int64
+		end		int64
+		bytes	int64
+	}{{0, 249, 250}, {-10, 9, 10}, {200, 1000, 50}, {300, 400, 0}} {
+		if bytes := RangeBytes(250, test.start, test.end); bytes != test.bytes {
+			t.Fatalf("bytes [%d, %d]: %d, expected %d", test.start, test.end, bytes, test.bytes)
+		}
+	}
+}
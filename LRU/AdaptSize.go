@@ -0,0 +1,179 @@
+package LRU
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+)
+
+/**
+	AdaptSize admission (Berger et al., NSDI 2017). A missed object is admitted with probability e^(-size / c).
+	Every interval requests, c is tuned with the Markov model of the paper: with request rate r and admission
+	probability a, an object is in the cache with probability
+		h = (1 - e^(-r * T)) * a / (e^(-r * T) + a * (1 - e^(-r * T)))
+	where the characteristic time T is chosen so that the expected bytes in cache equal the cache size.
+	c is set to the value maximizing the expected object hit ratio, found on a log-spaced grid and then refined
+	by golden-section search. Rates are measured in the last interval only. Objects are summed in the order of
+	their first request, so the same trace always gives the same c.
+	Unlike the other caches of this package, AdaptSize is a struct, so the box cache can own one as well.
+ */
+
+type adaptSizeStat struct {
+	size		int64
+	count		int64
+}
+
+/**
+	c after a tuning, and the number of requests observed until then.
+ */
+type CChange struct {
+	Request		int64
+	C			float64
+}
+
+type AdaptSize struct {
+	C			float64
+	cacheSize	float64
+	interval	int64
+	requests	int64				// in the current interval
+	observed	int64
+	stats		map[string]*adaptSizeStat
+	order		[]*adaptSizeStat	// stats in the order of the first request
+
+	random		*rand.Rand
+
+	CTime		[]CChange			// c after every tuning
+}
+
+/**
+	Create AdaptSize for a cache of cacheSize bytes, tuned every interval requests.
+ */
+func NewAdaptSize(cacheSize int64, interval int64, seed int64) *AdaptSize {
+	return &AdaptSize{
+		C:			float64(cacheSize) / 100,
+		cacheSize:	float64(cacheSize),
+		interval:	interval,
+		stats:		make(map[string]*adaptSizeStat),
+		random:		rand.New(rand.NewSource(seed)),
+		CTime:		make([]CChange, 0),
+	}
+}
+
+/**
+	Record one request for tuning. Should be called on every request, hit or miss.
+ */
+func (adapt *AdaptSize) Observe(object string, size int64) {
+	stat, ok := adapt.stats[object]
+	if !ok {
+		stat = &adaptSizeStat{}
+		adapt.stats[object] = stat
+		adapt.order = append(adapt.order, stat)
+	}
+	stat.size = size
+	stat.count++
+	adapt.requests++
+	adapt.observed++
+	if adapt.requests >= adapt.interval {
+		adapt.tune()
+		adapt.requests = 0
+		adapt.stats = make(map[string]*adaptSizeStat)
+		adapt.order = make([]*adaptSizeStat, 0)
+	}
+}
+
+/**
+	Admit a missed object with probability e^(-size / c).
+ */
+func (adapt *AdaptSize) Admit(object string, size int64) bool {
+	return adapt.random.Float64() < math.Exp(-float64(size) / adapt.C)
+}
+
+/**
+	Request an object from one of the caches of this package, e.g. with S2LRUContains and S2LRURequest.
+	Only admitted misses are inserted. Return true if it is a hit.
+ */
+func (adapt *AdaptSize) Request(contains func(object string, size string) bool,
+	request func(object string, size string) bool, object string, size string) bool {
+	objectSize, _ := strconv.ParseInt(size, 10, 64)
+	adapt.Observe(object, objectSize)
+	if contains(object, size) {
+		return request(object, size)
+	}
+	if adapt.Admit(object, objectSize) {
+		request(object, size)
+	}
+	return false
+}
+
+func (adapt *AdaptSize) tune() {
+	best, bestHit := adapt.C, adapt.hitRatio(adapt.C)
+	for c := 1.0; c <= adapt.cacheSize; c *= 2 {
+		if hit := adapt.hitRatio(c); hit > bestHit {
+			best, bestHit = c, hit
+		}
+	}
+
+	// golden-section search between the neighbours of the best grid point
+	low, high := best / 2, best * 2
+	ratio := (math.Sqrt(5) - 1) / 2
+	for step := 0; step < 10; step++ {
+		left := high - ratio * (high - low)
+		right := low + ratio * (high - low)
+		if adapt.hitRatio(left) > adapt.hitRatio(right) {
+			high = right
+		} else {
+			low = left
+		}
+	}
+	if mid := (low + high) / 2; adapt.hitRatio(mid) > bestHit {
+		best = mid
+	}
+	adapt.C = best
+	adapt.CTime = append(adapt.CTime, CChange{adapt.observed, best})
+}
+
+/**
+	Expected object hit ratio with parameter c.
+ */
+func (adapt *AdaptSize) hitRatio(c float64) float64 {
+	if len(adapt.order) == 0 {
+		return 0
+	}
+	// bisection on the characteristic time T (in requests), bytes in cache grow with T
+	low, high := 0.0, 1.0
+	for adapt.bytesInCache(c, high) < adapt.cacheSize && high < 1e15 {
+		high *= 2
+	}
+	for step := 0; step < 40; step++ {
+		mid := (low + high) / 2
+		if adapt.bytesInCache(c, mid) < adapt.cacheSize {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	hits := 0.0
+	for _, stat := range adapt.order {
+		hits += float64(stat.count) * adapt.inCache(stat, c, low)
+	}
+	return hits / float64(adapt.requests)
+}
+
+func (adapt *AdaptSize) bytesInCache(c float64, t float64) float64 {
+	bytes := 0.0
+	for _, stat := range adapt.order {
+		bytes += adapt.inCache(stat, c, t) * float64(stat.size)
+	}
+	return bytes
+}
+
+func (adapt *AdaptSize) inCache(stat *adaptSizeStat, c float64, t float64) float64 {
+	rate := float64(stat.count) / float64(adapt.requests)
+	admit := math.Exp(-float64(stat.size) / c)
+	miss := math.Exp(-rate * t)
+	if admit == 0 {
+		return 0
+	}
+	return (1 - miss) * admit / (miss + admit * (1 - miss))
+}
@@ -0,0 +1,33 @@
+package LRU
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestAdaptSizeTuningRepeats(t *testing.T) {
+	var runs [2][]CChange
+	for run := range runs {
+		adapt := NewAdaptSize(1 << 20, 1000, 1)
+		trace := rand.New(rand.NewSource(7))
+		for index := 0; index < 5000; index++ {
+			object := trace.Intn(2000)
+			adapt.Observe("o" + strconv.Itoa(object), int64(object % 100 + 1) * 1000)
+		}
+		runs[run] = adapt.CTime
+	}
+
+	if len(runs[0]) != 5 {
+		t.Fatalf("%d tunings in 5000 requests, expected 5", len(runs[0]))
+	}
+	for index, change := range runs[0] {
+		if change.Request != int64(index + 1) * 1000 {
+			t.Fatalf("tuning %d at request %d", index, change.Request)
+		}
+		// exact equality: the sums are taken in the same order every run
+		if change != runs[1][index] {
+			t.Fatalf("same trace, tuning %d gives %v and %v", index, change, runs[1][index])
+		}
+	}
+}
@@ -36,15 +36,34 @@ func LruCache(size int) {
 	//sizeMap = make(map[string]int, 0)
 }
 
-func Request(object string, size string) {
+func Request(object string, size string) bool {
 	fmt.Printf("Requested object: %s.\n", object)
+	return S2LRURequest(object, size)
+}
+
+/**
+	Check whether an up-to-date copy of the object is cached, without updating the queues.
+ */
+func S2LRUContains(object string, size string) bool {
+	objectSize, err := strconv.Atoi(size)
+	if err != nil {
+		return false
+	}
+	element, ok := objQueueMap[object]
+	return ok && element.pos.Value.(*Object).objectSize == objectSize
+}
 
+/**
+	Same as Request without printing. Return true if the object is cached and up-to-date.
+ */
+func S2LRURequest(object string, size string) bool {
 	// Question: need to update sizeMap or not when object is in the cache but the request is asking for a different size
 	objectSize, err := strconv.Atoi(size)
 	if err != nil {
-		fmt.Printf("Cannot convert size %s to integer.\n", objectSize)
+		fmt.Printf("Cannot convert size %s to integer.\n", size)
 	}
 
+	hit := false
 	element, ok := objQueueMap[object]
 	if ok {
 		// object is in cache, before updating the LRU queue, we need to make sure that this object is up-to-date.
@@ -59,10 +78,13 @@ func Request(object string, size string) {
 
 		// If it is out of date, then we think it is a miss, put it into the MRU position in cold queue
 		if objectSize != origSize {
+			// the old copy leaves its queue together with its size
 			if element.hot {
 				hotQueue.Remove(element.pos)
+				hotSize = hotSize - origSize
 			} else {
 				coldQueue.Remove(element.pos)
+				coldSize = coldSize - origSize
 			}
 			delete(objQueueMap, object)
 			coldQueue.PushBack(obj)
@@ -79,6 +101,7 @@ func Request(object string, size string) {
 			}
 		} else {
 			// Object is up-to-date
+			hit = true
 			if element.hot {
 				// object is in hot queue， moving it to MRU position doesn't change the hot cache size
 				hotQueue.Remove(element.pos)	// remove it from the queue
@@ -150,11 +173,13 @@ func Request(object string, size string) {
 			updateColdQueue()
 		}
 	}
+	return hit
 }
 
 func updateHotQueue() []*Object {
 	toBeTrans := make([]*Object, 0)
-	for e := hotQueue.Front(); e != nil && hotSize > maxCacheSize; e = e.Next() {
+	for e := hotQueue.Front(); e != nil && hotSize > maxCacheSize; {
+		next := e.Next()		// Remove clears the links of e
 		objectid := e.Value.(*Object).objectID
 		objSize := e.Value.(*Object).objectSize
 		hotQueue.Remove(e)		// remove from hot queue
@@ -165,6 +190,7 @@ func updateHotQueue() []*Object {
 			objectID: 		objectid,
 		}
 		toBeTrans = append(toBeTrans, evicted)		// add it to slice which will be added to cold queue
+		e = next
 	}
 	return toBeTrans
 }
@@ -182,12 +208,14 @@ func updateHotQueue() []*Object {
 //}
 
 func updateColdQueue() {
-	for e := coldQueue.Front(); e != nil && coldSize > maxCacheSize; e = e.Next() {
+	for e := coldQueue.Front(); e != nil && coldSize > maxCacheSize; {
+		next := e.Next()		// Remove clears the links of e
 		objectid := e.Value.(*Object).objectID
 		objectsize := e.Value.(*Object).objectSize
 		coldQueue.Remove(e)
 		delete(objQueueMap, objectid)
 		coldSize = coldSize - objectsize
+		e = next
 	}
 }
 
@@ -0,0 +1,61 @@
+package LRU
+
+import (
+	"strconv"
+	"testing"
+)
+
+/**
+	Bytes held by a queue, counted from its objects.
+ */
+func queueBytes(t *testing.T, hot bool) int {
+	t.Helper()
+	queue := coldQueue
+	if hot {
+		queue = hotQueue
+	}
+	bytes := 0
+	for element := queue.Front(); element != nil; element = element.Next() {
+		bytes += element.Value.(*Object).objectSize
+	}
+	return bytes
+}
+
+func TestS2LRUColdEvictsUntilFit(t *testing.T) {
+	LruCache(1000)
+	for index := 0; index < 5; index++ {
+		S2LRURequest("o" + strconv.Itoa(index), "100")
+	}
+	// all five small objects leave the 500 byte cold queue
+	S2LRURequest("big", "500")
+	if coldSize != 500 || queueBytes(t, false) != 500 || len(objQueueMap) != 1 {
+		t.Fatalf("cold queue holds %d bytes, accounted %d, %d objects cached", queueBytes(t, false), coldSize, len(objQueueMap))
+	}
+}
+
+func TestS2LRUHotEvictsUntilFit(t *testing.T) {
+	LruCache(1000)
+	for index := 0; index < 5; index++ {
+		S2LRURequest("o" + strconv.Itoa(index), "100")
+		S2LRURequest("o" + strconv.Itoa(index), "100")
+	}
+	// big is promoted and pushes all five objects back into the cold queue
+	S2LRURequest("big", "500")
+	S2LRURequest("big", "500")
+	if hotSize != 500 || queueBytes(t, true) != 500 || coldSize != 500 || queueBytes(t, false) != 500 {
+		t.Fatalf("hot queue %d bytes, accounted %d, cold queue %d bytes, accounted %d",
+			queueBytes(t, true), hotSize, queueBytes(t, false), coldSize)
+	}
+}
+
+func TestS2LRUSizeChange(t *testing.T) {
+	LruCache(1000)
+	S2LRURequest("a", "100")
+	S2LRURequest("a", "100")
+	if S2LRURequest("a", "200") || !S2LRUContains("a", "200") {
+		t.Fatalf("out of date copy served")
+	}
+	if hotSize != 0 || coldSize != 200 || queueBytes(t, true) != 0 || queueBytes(t, false) != 200 {
+		t.Fatalf("hot queue %d bytes, cold queue %d bytes after a size change", hotSize, coldSize)
+	}
+}
@@ -0,0 +1,179 @@
+package LRU
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+)
+
+/**
+	S3-FIFO: a small probationary FIFO (10% of the cache), a main FIFO and a ghost FIFO which only keeps ids.
+	New objects go to the small queue unless they are found in the ghost queue, then they go to the main queue.
+	Objects leaving the small queue are moved to the main queue if they were hit, otherwise their ids go to
+	the ghost queue. Objects leaving the main queue are reinserted with a lower frequency if they were hit.
+ */
+
+const s3MaxFreq = 3
+
+type s3Object struct {
+	objectID	string
+	objectSize	int
+	freq		int
+	main		bool		// in main queue or small queue
+}
+
+var (
+	s3SmallMax		int
+	s3MainMax		int
+	s3SmallSize		int
+	s3MainSize		int
+	s3GhostSize		int
+	s3Small			*list.List		// oldest object in the front, newest in the end
+	s3Main			*list.List
+	s3Ghost			*list.List		// holds evicted *s3Object, only id and size are used
+	s3Map			map[string]*list.Element
+	s3GhostMap		map[string]*list.Element
+)
+
+func S3FifoCache(size int) {
+	s3SmallMax = size / 10
+	s3MainMax = size - s3SmallMax
+	s3SmallSize = 0
+	s3MainSize = 0
+	s3GhostSize = 0
+	s3Small = list.New()
+	s3Main = list.New()
+	s3Ghost = list.New()
+	s3Map = make(map[string]*list.Element)
+	s3GhostMap = make(map[string]*list.Element)
+}
+
+/**
+	Request one object. Return true if it is a hit.
+ */
+func S3FifoRequest(object string, size string) bool {
+	objectSize, err := strconv.Atoi(size)
+	if err != nil {
+		fmt.Printf("Cannot convert size %s to integer.\n", size)
+	}
+
+	element, ok := s3Map[object]
+	if ok {
+		obj := element.Value.(*s3Object)
+		if obj.objectSize == objectSize {
+			if obj.freq < s3MaxFreq {
+				obj.freq++
+			}
+			return true
+		}
+		// out of date
+		s3Remove(element)
+	}
+
+	if objectSize > s3SmallMax + s3MainMax {
+		return false
+	}
+	for s3SmallSize + s3MainSize + objectSize > s3SmallMax + s3MainMax {
+		s3Evict()
+	}
+
+	newObject := &s3Object{object, objectSize, 0, false}
+	if ghost, inGhost := s3GhostMap[object]; inGhost {
+		s3RemoveGhost(ghost)
+		newObject.main = true
+		s3Main.PushBack(newObject)
+		s3Map[object] = s3Main.Back()
+		s3MainSize += objectSize
+	} else {
+		s3Small.PushBack(newObject)
+		s3Map[object] = s3Small.Back()
+		s3SmallSize += objectSize
+	}
+	return false
+}
+
+func s3Evict() {
+	if s3SmallSize >= s3SmallMax || s3Main.Len() == 0 {
+		s3EvictSmall()
+	} else {
+		s3EvictMain()
+	}
+}
+
+/**
+	Evict the oldest object in small queue. If it was hit, move it to main queue instead.
+ */
+func s3EvictSmall() {
+	element := s3Small.Front()
+	obj := element.Value.(*s3Object)
+	s3Small.Remove(element)
+	s3SmallSize -= obj.objectSize
+
+	if obj.freq > 0 {
+		obj.freq = 0
+		obj.main = true
+		s3Main.PushBack(obj)
+		s3Map[obj.objectID] = s3Main.Back()
+		s3MainSize += obj.objectSize
+		return
+	}
+	delete(s3Map, obj.objectID)
+
+	// ghost queue remembers as many bytes as main queue holds
+	s3Ghost.PushBack(obj)
+	s3GhostMap[obj.objectID] = s3Ghost.Back()
+	s3GhostSize += obj.objectSize
+	for s3GhostSize > s3MainMax {
+		s3RemoveGhost(s3Ghost.Front())
+	}
+}
+
+/**
+	Evict from main queue. Objects which were hit are reinserted with a lower frequency.
+ */
+func s3EvictMain() {
+	for {
+		element := s3Main.Front()
+		obj := element.Value.(*s3Object)
+		if obj.freq > 0 {
+			obj.freq--
+			s3Main.MoveToBack(element)
+			continue
+		}
+		s3Main.Remove(element)
+		delete(s3Map, obj.objectID)
+		s3MainSize -= obj.objectSize
+		return
+	}
+}
+
+func s3Remove(element *list.Element) {
+	obj := element.Value.(*s3Object)
+	if obj.main {
+		s3Main.Remove(element)
+		s3MainSize -= obj.objectSize
+	} else {
+		s3Small.Remove(element)
+		s3SmallSize -= obj.objectSize
+	}
+	delete(s3Map, obj.objectID)
+}
+
+func s3RemoveGhost(element *list.Element) {
+	obj := element.Value.(*s3Object)
+	s3Ghost.Remove(element)
+	delete(s3GhostMap, obj.objectID)
+	s3GhostSize -= obj.objectSize
+}
+
+/**
+	Check whether an up-to-date copy of the object is cached, without updating its frequency.
+ */
+func S3FifoContains(object string, size string) bool {
+	objectSize, err := strconv.Atoi(size)
+	if err != nil {
+		return false
+	}
+	element, ok := s3Map[object]
+	return ok && element.Value.(*s3Object).objectSize == objectSize
+}
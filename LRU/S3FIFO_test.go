@@ -0,0 +1,52 @@
+package LRU
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestS3FifoQueues(t *testing.T) {
+	// 1 byte small queue, 9 bytes main queue
+	S3FifoCache(10)
+	for index := 0; index < 10; index++ {
+		S3FifoRequest("o" + strconv.Itoa(index), "1")
+	}
+	if !S3FifoRequest("o0", "1") {
+		t.Fatalf("o0 is not cached")
+	}
+	// o0 was hit and moves to main queue, o1 was not and leaves a ghost
+	S3FifoRequest("o10", "1")
+	if element, ok := s3Map["o0"]; !ok || !element.Value.(*s3Object).main {
+		t.Fatalf("o0 is not in main queue")
+	}
+	if _, ok := s3Map["o1"]; ok {
+		t.Fatalf("one-hit o1 is still cached")
+	}
+	if _, ok := s3GhostMap["o1"]; !ok {
+		t.Fatalf("o1 is not in ghost queue")
+	}
+
+	// ghost hit goes straight to main queue
+	if S3FifoRequest("o1", "1") {
+		t.Fatalf("ghost served as a hit")
+	}
+	if element, ok := s3Map["o1"]; !ok || !element.Value.(*s3Object).main {
+		t.Fatalf("o1 is not readmitted into main queue")
+	}
+	if _, ok := s3GhostMap["o1"]; ok {
+		t.Fatalf("o1 is still in ghost queue")
+	}
+	if s3SmallSize + s3MainSize != 10 || s3MainSize != 2 {
+		t.Fatalf("small queue %d bytes, main queue %d bytes", s3SmallSize, s3MainSize)
+	}
+}
+
+func TestS3FifoGhostBounded(t *testing.T) {
+	S3FifoCache(10)
+	for index := 0; index < 1000; index++ {
+		S3FifoRequest("o" + strconv.Itoa(index), "1")
+	}
+	if s3GhostSize > s3MainMax || s3Ghost.Len() != len(s3GhostMap) {
+		t.Fatalf("ghost queue holds %d bytes, %d ids, %d in the map", s3GhostSize, s3Ghost.Len(), len(s3GhostMap))
+	}
+}
@@ -0,0 +1,105 @@
+package LRU
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+)
+
+/**
+	SIEVE: one FIFO queue and a hand. A hit only sets the visited bit of the object, it never moves it.
+	On eviction the hand moves from the oldest object towards the newest one, clearing visited bits,
+	and evicts the first object which was not visited. The hand stays where it stopped for the next eviction.
+ */
+
+type sieveObject struct {
+	objectID	string
+	objectSize	int
+	visited		bool
+}
+
+var (
+	sieveMaxSize	int
+	sieveSize		int
+	sieveQueue		*list.List					// oldest object in the front, newest in the end
+	sieveMap		map[string]*list.Element	// object id --> position in the queue
+	sieveHand		*list.Element				// next candidate for eviction, nil --> front of the queue
+)
+
+func SieveCache(size int) {
+	sieveMaxSize = size
+	sieveSize = 0
+	sieveQueue = list.New()
+	sieveMap = make(map[string]*list.Element)
+	sieveHand = nil
+}
+
+/**
+	Request one object. Return true if it is a hit.
+	If the cached copy has a different size, it is out of date and the request is a miss.
+ */
+func SieveRequest(object string, size string) bool {
+	objectSize, err := strconv.Atoi(size)
+	if err != nil {
+		fmt.Printf("Cannot convert size %s to integer.\n", size)
+	}
+
+	element, ok := sieveMap[object]
+	if ok {
+		obj := element.Value.(*sieveObject)
+		if obj.objectSize == objectSize {
+			obj.visited = true
+			return true
+		}
+		sieveRemove(element)
+	}
+
+	if objectSize > sieveMaxSize {
+		return false
+	}
+	for sieveSize + objectSize > sieveMaxSize {
+		sieveEvict()
+	}
+	sieveQueue.PushBack(&sieveObject{object, objectSize, false})
+	sieveMap[object] = sieveQueue.Back()
+	sieveSize += objectSize
+	return false
+}
+
+func sieveEvict() {
+	hand := sieveHand
+	if hand == nil {
+		hand = sieveQueue.Front()
+	}
+	for hand.Value.(*sieveObject).visited {
+		hand.Value.(*sieveObject).visited = false
+		hand = hand.Next()
+		if hand == nil {
+			hand = sieveQueue.Front()
+		}
+	}
+	sieveHand = hand
+	sieveRemove(hand)
+}
+
+func sieveRemove(element *list.Element) {
+	if sieveHand == element {
+		sieveHand = element.Next()
+	}
+	obj := element.Value.(*sieveObject)
+	sieveQueue.Remove(element)
+	delete(sieveMap, obj.objectID)
+	sieveSize -= obj.objectSize
+}
+
+/**
+	Check whether an up-to-date copy of the object is cached, without marking it visited.
+ */
+func SieveContains(object string, size string) bool {
+	objectSize, err := strconv.Atoi(size)
+	if err != nil {
+		return false
+	}
+	element, ok := sieveMap[object]
+	return ok && element.Value.(*sieveObject).objectSize == objectSize
+}
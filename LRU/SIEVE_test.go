@@ -0,0 +1,32 @@
+package LRU
+
+import "testing"
+
+func TestSieveKeepsVisited(t *testing.T) {
+	SieveCache(3)
+	for _, object := range []string{"a", "b", "c"} {
+		SieveRequest(object, "1")
+	}
+	if !SieveRequest("a", "1") {
+		t.Fatalf("a is not cached")
+	}
+	// the hand passes a, clears its bit and evicts b, then stops at c
+	SieveRequest("d", "1")
+	SieveRequest("e", "1")
+	for object, cached := range map[string]bool{"a": true, "b": false, "c": false, "d": true, "e": true} {
+		if SieveContains(object, "1") != cached {
+			t.Fatalf("%s cached: %t, expected %t", object, !cached, cached)
+		}
+	}
+	if sieveSize != 3 || sieveQueue.Len() != 3 {
+		t.Fatalf("%d objects of %d bytes cached, expected 3", sieveQueue.Len(), sieveSize)
+	}
+}
+
+func TestSieveSizeChange(t *testing.T) {
+	SieveCache(10)
+	SieveRequest("a", "1")
+	if SieveRequest("a", "2") || !SieveRequest("a", "2") || sieveSize != 2 {
+		t.Fatalf("out of date copy of %d bytes served", sieveSize)
+	}
+}
@@ -38,7 +38,7 @@ func request(object string, size string) {
 	} else {
 		objectSize, err := strconv.Atoi(size)
 		if err != nil {
-			fmt.Printf("Cannot convert size %s to integer.\n", objectSize)
+			fmt.Printf("Cannot convert size %s to integer.\n", size)
 		} else {
 			objSizeMap[object] = objectSize
 		}
@@ -1,4 +1,4 @@
-package base
+package LRU
 
 import (
 	"fmt"
@@ -1,4 +1,4 @@
-package base
+package LRU
 
 import (
 	"fmt"
@@ -36,8 +36,7 @@ func printElement(e *list.Element) {
 func PrintQueue(queue *list.List) {
 	if flag > 0 {
 		for element := queue.Front(); element != nil; element = element.Next() {
-			logger.Printf("Box id %s holds %d items, current size is %d.\n",
-				element.Value.(*LogStructured.Box))
+			logger.Printf("Box %v.\n", element.Value.(*LogStructured.Box))
 		}
 		logger.Println()
 	}
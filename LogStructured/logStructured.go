@@ -6,8 +6,6 @@ import (
 	"fmt"
 )
 
-//const maxBoxSize = 16
-const maxBoxSize = 104857600		// 100 MB
 
 type Object struct {
 	objectId 	string
@@ -27,6 +25,7 @@ type QueuePos struct {
 }
 
 var (
+	maxBoxSize		int64 = 104857600	// 100 MB
 	hotQueue		*list.List		// holds box
 	coldQueue		*list.List
 	hotSize 		int64
@@ -104,9 +103,17 @@ func StartUp(cacheSize int64, number int, upperBounds []int64) {
 }
 
 /**
-	When a new object comes in, check whether it is cached or not. If it's cached, then update the LRU list
+	Set the box (erase block) size in Bytes. Should be called before StartUp.
  */
-func NewRequest(id string, size string) {
+func SetBoxSize(size int64) {
+	maxBoxSize = size
+}
+
+/**
+	When a new object comes in, check whether it is cached or not. If it's cached, then update the LRU list.
+	Return true if it is a hit.
+ */
+func NewRequest(id string, size string) bool {
 	// In flash level: size --> box indexes in flash. In box level: object id --> whether the object is in cache or not
 	numRequest++
 	//fmt.Printf("New request with object id: %s and size: %s. Total requests: %d\n", id, size, numRequest)
@@ -120,7 +127,7 @@ func NewRequest(id string, size string) {
 	DPrintf("%s should be put into open box with upper bound %d.\n", id, bound)
 	if bound == -1 {
 		DPrintf("Object size %s exceeds the maximum box size.\n", size)
-		return
+		return false
 	}
 
 	// First check whether the object is in the open box or not. If it's already in the open box,
@@ -207,12 +214,12 @@ func NewRequest(id string, size string) {
 			DPrintf("Open box %d with upper bound %d holds %d objects, and current offset is %d.\n",
 				openBox.boxId, openBox.upperBound, len(openBox.objOffsetMap), openBox.currSize)
 		}
-	} else {
-		// requested object is in open box.
-		hits++
-		DPrintf("Hits: %d.\n", hits)
+		return foundObject
 	}
-
+	// requested object is in open box.
+	hits++
+	DPrintf("Hits: %d.\n", hits)
+	return true
 }
 
 /**
@@ -0,0 +1,29 @@
+package ObjectBased
+
+import (
+	"awesomeProject/LRU"
+	"fmt"
+)
+
+/**
+	AdaptSize admission (model "adaptSize") for the box cache, see LRU.AdaptSize.
+	Every request is observed, also hits and requests to open boxes, and c is tuned for the flash capacity.
+ */
+
+var adaptSize		*LRU.AdaptSize
+
+/**
+	Set up AdaptSize, tuned every interval requests. Should be called after StartUp, its random admission is
+	seeded from the run, see RunState.
+ */
+func AdaptSizeSetUp(interval int64) {
+	adaptSize = LRU.NewAdaptSize(flashCapacity(), interval, run.source("adaptSize").Int63())
+}
+
+/**
+	Return c after every tuning, with the request it was tuned at.
+ */
+func GetAdaptSizeResults() []LRU.CChange {
+	fmt.Printf("AdaptSize:: seed: %d, tunings: %d, current c: %f.\n", run.Seed, len(adaptSize.CTime), adaptSize.C)
+	return adaptSize.CTime
+}
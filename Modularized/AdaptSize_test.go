@@ -0,0 +1,33 @@
+package ObjectBased
+
+import "testing"
+
+func runAdaptSize(t *testing.T, s int64) (int64, int64) {
+	SetSeed(s)
+	testStartUp(t)
+	AdaptSizeSetUp(2000)
+	replay("adaptSize", 20000)
+	return hits, sealedBytes + openBytes
+}
+
+func TestAdaptSizeFollowsSeed(t *testing.T) {
+	defer SetSeed(1)
+	hits1, written1 := runAdaptSize(t, 1)
+	changes := adaptSize.CTime
+	hits2, written2 := runAdaptSize(t, 1)
+	if hits1 != hits2 || written1 != written2 {
+		t.Fatalf("same seed, different runs: hits %d vs %d, bytes %d vs %d", hits1, hits2, written1, written2)
+	}
+	if len(changes) == 0 || len(changes) != len(adaptSize.CTime) {
+		t.Fatalf("c tuned %d and %d times", len(changes), len(adaptSize.CTime))
+	}
+	for index, change := range changes {
+		if change != adaptSize.CTime[index] {
+			t.Fatalf("same seed, tuning %d gives %v and %v", index, change, adaptSize.CTime[index])
+		}
+	}
+	hits3, written3 := runAdaptSize(t, 2)
+	if hits1 == hits3 && written1 == written3 {
+		t.Fatalf("seeds 1 and 2 give the same run: hits %d, bytes %d", hits1, written1)
+	}
+}
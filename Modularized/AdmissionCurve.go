@@ -0,0 +1,128 @@
+package ObjectBased
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+/**
+	Admission curves of the improved probability controller. A curve maps the bytes used in the current quantum
+	and the budget (allowance of writeBudget, always positive) to the probability of admitting a missed object.
+	The curve is selected by the model name given to AdmissionSetUp, which resolves it once for the run. Until then,
+	lameDuck is used. Built in:
+	1. lameDuck: line, 1 - used / (K * budget).
+	2. angryBird: logarithm, log(budget - used) / log(budget).
+	3. spicyChicken: exponential, e^(-used / budget).
+	4. logistic: 1 / (1 + e^(steepness * (used / budget - midpoint))).
+	5. piecewiseLinear: admit everything up to half of the budget, then a line down to 0 at the budget.
+	More curves can be added with RegisterProbCurve, e.g. with NewPiecewiseLinear.
+ */
+
+type AdmissionCurve func(used int64, budget int64) float64
+
+const (
+	logisticSteepness	= 10.0
+	logisticMidpoint	= 0.5
+)
+
+var (
+	probCurves = map[string]AdmissionCurve{
+		"lameDuck":			curveLine,
+		"angryBird":		curveLog,
+		"spicyChicken":		curveExp,
+		"logistic":			curveLogistic,
+		"piecewiseLinear":	mustPiecewiseLinear([]float64{0, 0.5, 1}, []float64{1, 1, 0}),
+	}
+	admissionCurve		= curveLine		// curve of the current run, set by AdmissionSetUp
+)
+
+func curveLine(used int64, budget int64) float64 {
+	slack := K
+	if slack < 1 {
+		slack = 1
+	}
+	return -1 / float64(int64(slack) * budget) * float64(used) + 1
+}
+
+func curveLog(used int64, budget int64) float64 {
+	return math.Log(float64(budget) - float64(used)) / math.Log(float64(budget))
+}
+
+func curveExp(used int64, budget int64) float64 {
+	return math.Exp(-float64(used) / float64(budget))
+}
+
+func curveLogistic(used int64, budget int64) float64 {
+	return 1 / (1 + math.Exp(logisticSteepness * (float64(used) / float64(budget) - logisticMidpoint)))
+}
+
+/**
+	Piecewise-linear curve through the points (xs[i], ys[i]), where x is used / budget. xs should increase.
+	Before the first point and after the last one, the curve is flat.
+ */
+func NewPiecewiseLinear(xs []float64, ys []float64) (AdmissionCurve, error) {
+	if len(xs) == 0 || len(xs) != len(ys) {
+		return nil, fmt.Errorf("piecewise-linear curve needs the same number of xs and ys, got %d and %d",
+			len(xs), len(ys))
+	}
+	for i := range xs {
+		if i > 0 && xs[i] <= xs[i - 1] {
+			return nil, fmt.Errorf("xs of a piecewise-linear curve should increase: %v", xs)
+		}
+		if ys[i] < 0 || ys[i] > 1 {
+			return nil, fmt.Errorf("ys of a piecewise-linear curve should be probabilities: %v", ys)
+		}
+	}
+	points := len(xs)
+	return func(used int64, budget int64) float64 {
+		x := float64(used) / float64(budget)
+		if x <= xs[0] {
+			return ys[0]
+		}
+		for i := 1; i < points; i++ {
+			if x <= xs[i] {
+				return ys[i - 1] + (ys[i] - ys[i - 1]) * (x - xs[i - 1]) / (xs[i] - xs[i - 1])
+			}
+		}
+		return ys[points - 1]
+	}, nil
+}
+
+func mustPiecewiseLinear(xs []float64, ys []float64) AdmissionCurve {
+	curve, err := NewPiecewiseLinear(xs, ys)
+	if err != nil {
+		panic(err)
+	}
+	return curve
+}
+
+/**
+	Add an admission curve under name, which can then be used as a model.
+ */
+func RegisterProbCurve(name string, curve AdmissionCurve) error {
+	if name == "" || curve == nil {
+		return fmt.Errorf("admission curve needs a name and a function")
+	}
+	if _, ok := probCurves[name]; ok {
+		return fmt.Errorf("admission curve %s already exists", name)
+	}
+	probCurves[name] = curve
+	return nil
+}
+
+/**
+	Return the admission curve of name, or an error if there is none.
+ */
+func ProbCurve(name string) (AdmissionCurve, error) {
+	curve, ok := probCurves[name]
+	if !ok {
+		names := make([]string, 0, len(probCurves))
+		for known := range probCurves {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("wrong choice of probability %s, should be one of %v", name, names)
+	}
+	return curve, nil
+}
@@ -0,0 +1,88 @@
+package ObjectBased
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAdmissionSetUp(t *testing.T) {
+	testStartUp(t)
+	policies := []string{"TIRE", "kHit", "flashield", "PID", "adaptSize"}
+	for _, model := range policies {
+		if AdmissionSetUp(model) == nil {
+			t.Fatalf("no error for %s before it is set up", model)
+		}
+	}
+	TireSetUp(4, 9, 100, false)
+	KHitSetUp(2, 1000, 1000, 0.01)
+	FlashieldSetUp(100, 1000, 0.5, 0.1, 0)
+	PIDSetUp(1000, 0.1, 0.1, 0, "prob")
+	AdaptSizeSetUp(1000)
+	for _, model := range append(policies, "lameDuck", "angryBird", "spicyChicken", "logistic", "piecewiseLinear") {
+		if err := AdmissionSetUp(model); err != nil {
+			t.Fatalf("model %s: %v", model, err)
+		}
+	}
+	if AdmissionSetUp("lameDuk") == nil {
+		t.Fatalf("no error for an unknown model")
+	}
+	if AdmissionSetUp("spicyChicken"); admissionCurve(1000, 1000) != curveExp(1000, 1000) {
+		t.Fatalf("spicyChicken is not the curve of the run")
+	}
+
+	// a new run forgets the policies of the last one
+	testStartUp(t)
+	if AdmissionSetUp("TIRE") == nil {
+		t.Fatalf("no error for TIRE after StartUp")
+	}
+}
+
+func TestBuiltInCurves(t *testing.T) {
+	K = 1
+	for name, curve := range probCurves {
+		if name != "angryBird" && math.Abs(curve(0, 1000) - 1) > 0.01 {
+			t.Fatalf("%s admits %f with nothing used", name, curve(0, 1000))
+		}
+		last := 1.0
+		for used := int64(0); used < 1000; used += 50 {
+			prob := curve(used, 1000)
+			if prob < 0 || prob > last + 1e-9 {
+				t.Fatalf("%s is not a decreasing probability at %d: %f after %f", name, used, prob, last)
+			}
+			last = prob
+		}
+	}
+}
+
+func TestPiecewiseLinear(t *testing.T) {
+	curve, err := NewPiecewiseLinear([]float64{0.2, 0.6}, []float64{1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, point := range [][2]float64{{0, 1}, {200, 1}, {400, 0.5}, {600, 0}, {900, 0}} {
+		if prob := curve(int64(point[0]), 1000); math.Abs(prob - point[1]) > 1e-9 {
+			t.Fatalf("curve(%.0f) = %f, expected %f", point[0], prob, point[1])
+		}
+	}
+	if _, err := NewPiecewiseLinear([]float64{0, 0}, []float64{1, 0}); err == nil {
+		t.Fatalf("no error for xs which do not increase")
+	}
+	if RegisterProbCurve("lameDuck", curveExp) == nil {
+		t.Fatalf("a built-in curve was replaced")
+	}
+}
+
+func TestImprovedProbStopsAtBudget(t *testing.T) {
+	testStartUp(t)
+	if err := AdmissionSetUp("spicyChicken"); err != nil {
+		t.Fatal(err)
+	}
+	WriteBudgetSetUp(1000, 1000, 0, 1000000)
+	writeBudget.tick()
+	writeBudget.chargeAdmission(1001)
+	for try := 0; try < 100; try++ {
+		if admission("spicyChicken", "a", 10) {
+			t.Fatalf("admitted over the budget")
+		}
+	}
+}
@@ -2,7 +2,6 @@ package ObjectBased
 
 import (
 	"math"
-	"math/rand"
 	"fmt"
 )
 
@@ -12,21 +11,17 @@ var (
 	avgProb 		float64
 	admitMiss		int64
 	totalMiss		int64
-	written			int64
-	budget 			int64
-	quotaABear		int64
+	avgProbQuantum	int64		// last quantum of writeBudget seen by updateAvgProb
 )
 
 /**
-	Set up.
+	Set up. The budget is writeBudget.
  */
-func AngryBearSetUp(quota int64) {
-	quotaABear = quota
-	written = 0
+func AngryBearSetUp() {
 	admitMiss = 0
 	totalMiss = 0
-	budget = quota
 	avgProb = 1
+	avgProbQuantum = 0
 }
 
 /**
@@ -34,29 +29,30 @@ func AngryBearSetUp(quota int64) {
  */
 func angryBearProb() float64 {
 	var prob float64
-	prob = math.Log(float64(budget - written)) / math.Log(float64(budget))
+	budget := writeBudget.Allowance()
+	prob = math.Log(float64(budget - writeBudget.Used())) / math.Log(float64(budget))
 	return prob
 }
 
 /**
-	Update the average admission probability and budget. The first 250 million requests are warm up phase.
+	Update the average admission probability when one quantum of writeBudget finishes.
+	The first 250 million requests are warm up phase.
  */
 func updateAvgProb() {
 	// Interval is not finished or warm up phase
-	if numRequest % Epoch != 0 || numRequest < 250 * Epoch {
+	if writeBudget.quanta == avgProbQuantum {
 		return
 	}
 	DFmtPrintf("updateAvgProb:: number of requests: %d. Original avgProb: %f, written: %d, budget: %d. Total miss: %d, admitted: %d.\n",
-		numRequest, avgProb, written, budget, totalMiss, admitMiss)
-	if numRequest == 250 * Epoch {
+		numRequest, avgProb, writeBudget.lastUsed, writeBudget.Allowance(), totalMiss, admitMiss)
+	if avgProbQuantum == 0 {
 		avgProb = 1
 	} else {
 		avgProb = float64(admitMiss) / (float64(totalMiss) * avgProb)
 	}
+	avgProbQuantum = writeBudget.quanta
 
-	budget = quotaABear + budget - written
-	DFmtPrintf("updateAvgProb:: current avgProb is %f, current budget is %d.\n", avgProb, budget)
-	written = 0
+	DFmtPrintf("updateAvgProb:: current avgProb is %f, current budget is %d.\n", avgProb, writeBudget.Allowance())
 	admitMiss = 0
 	totalMiss = 0
 }
@@ -69,9 +65,8 @@ func admissionControlAngryBear(size int64) bool {
 	prob := angryBearProb()
 
 	if prob > 0 {
-		random := rand.Float64()
+		random := run.random.Float64()
 		if random < prob {
-			written += size
 			return true
 		} else {
 			return false
@@ -82,7 +77,7 @@ func admissionControlAngryBear(size int64) bool {
 }
 
 func warmUpAngryBear(size int64) bool {
-	if numRequest / Epoch < 250 {
+	if numRequest < warmUpRequests {
 		return true
 	}
 	return admissionControlAngryBear(size)
@@ -104,13 +99,14 @@ func getResultsWithTimeFineGrain() {
 		SealedBoxNumber = append(SealedBoxNumber, numSeal)
 		HitRatioTime = append(HitRatioTime, float64(hits) / float64(numRequest))
 		HitBytesRatioTime = append(HitBytesRatioTime, float64(hitBytes) / float64(reqBytes))
+		IndexMemoryTime = append(IndexMemoryTime, indexMemory())
 		//MissBytesRatioTime = append(MissBytesRatioTime, float64(MissBytes) / float64(reqBytes))
 	}
 }
 
 /**
 	Return experiment results
-	1. WCR: waste cache ratio, percentage of wasted space --> bytes of fragmentation / total used cache size
+	1. WCR: waste cache ratio, percentage of wasted space --> bytes of fragmentation / total sealed box bytes
 	2. SBRR: sealed box request ratio, #sealed boxes / #requests
 	3. OHR: object hit ratio, #read hit / #requests
 	4. BHR: bytes hit ratio, #hit bytes / #requests
@@ -118,9 +114,11 @@ func getResultsWithTimeFineGrain() {
 func GetResultsFineGrain() (float64, float64, float64, float64) {
 	DPrintf("numSeal: %d, numRequest: %d, hits: %d, hit bytes: %d, totoal bytes: %d.\n",
 		numSeal, numRequest, hits, hitBytes, reqBytes)
-	fmt.Printf("fragRation: %f, numSeal: %d, numRequest: %d, hits: %d, hitBytes: %d, reqBytes: %d.\n",
-		fragRatio, numSeal, numRequest, hits, hitBytes, reqBytes)
-	WCR := fragRatio / float64(numSeal)
+	fmt.Printf("fragRation: %f, numSeal: %d, numRequest: %d, hits: %d, hitBytes: %d, reqBytes: %d, seed: %d.\n",
+		fragRatio, numSeal, numRequest, hits, hitBytes, reqBytes, run.Seed)
+	printBeladyResults()
+	printClassResults()
+	WCR := float64(fragBytes) / float64(sealedBytes)
 	SBRR := float64(numSeal) / float64(numRequest)
 	OHR := float64(hits) / float64(numRequest)
 	BHR := float64(hitBytes) / float64(reqBytes)
@@ -0,0 +1,204 @@
+package ObjectBased
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/**
+	Offline oracle (Belady / OPT). The whole trace is loaded first so that the next access time of every
+	request is known. Two caches are simulated:
+	1. MIN: capacity in number of objects, evict the object whose next request is furthest in the future.
+	2. Size-aware: capacity in bytes, evict the furthest next request first and break ties by the larger object.
+	Objects which are never requested again are not admitted at all (bypass).
+	Results are upper bounds for the online policies and are printed by GetResults once RunBelady is done.
+ */
+
+const noNextAccess = math.MaxInt64
+
+type optEntry struct {
+	object		int32		// index of the object
+	next		int64		// next access time of the object
+	size		int64
+}
+
+// max heap: furthest next access on top, then the larger object
+type optHeap []*optEntry
+
+func (h optHeap) Len() int { return len(h) }
+func (h optHeap) Less(i, j int) bool {
+	if h[i].next != h[j].next {
+		return h[i].next > h[j].next
+	}
+	return h[i].size > h[j].size
+}
+func (h optHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *optHeap) Push(x interface{}) { *h = append(*h, x.(*optEntry)) }
+func (h *optHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n - 1]
+	*h = old[:n - 1]
+	return entry
+}
+
+var (
+	optObjects		[]int32			// request index --> object index
+	optSizes		[]int64			// request index --> object size
+	optNext			[]int64			// request index --> next request index of the same object
+	optReqBytes		int64
+
+	/* results */
+	beladyDone		bool
+	minOHR			float64
+	minBHR			float64
+	sizeOptOHR		float64
+	sizeOptBHR		float64
+)
+
+/**
+	Load the trace and precompute the next access time of every request.
+	Each line of the trace is "timestamp id size", the same format as the online simulators use.
+ */
+func BeladySetUp(filePath string) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		log.Fatalf("Cannot open file %s --> %s.\n", filePath, err)
+	}
+	defer file.Close()
+
+	objIndex := make(map[string]int32)
+	optObjects = make([]int32, 0)
+	optSizes = make([]int64, 0)
+	optReqBytes = 0
+	beladyDone = false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		tokens := strings.Fields(scanner.Text())
+		if len(tokens) < 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(tokens[2], 10, 64)
+		if err != nil {
+			DPrintf("BeladySetUp:: size %s cannot be converted to int64 type with error %s.\n", tokens[2], err)
+		}
+		index, ok := objIndex[tokens[1]]
+		if !ok {
+			index = int32(len(objIndex))
+			objIndex[tokens[1]] = index
+		}
+		optObjects = append(optObjects, index)
+		optSizes = append(optSizes, size)
+		optReqBytes += size
+	}
+
+	// backward pass: next access of request t is the closest later request of the same object
+	optNext = make([]int64, len(optObjects))
+	lastSeen := make(map[int32]int64, len(objIndex))
+	for t := len(optObjects) - 1; t >= 0; t-- {
+		next, ok := lastSeen[optObjects[t]]
+		if !ok {
+			next = noNextAccess
+		}
+		optNext[t] = next
+		lastSeen[optObjects[t]] = int64(t)
+	}
+	DFmtPrintf("BeladySetUp:: %d requests, %d distinct objects.\n", len(optObjects), len(objIndex))
+}
+
+/**
+	Run both oracles on the loaded trace.
+	objCapacity: number of objects the MIN cache can hold.
+	byteCapacity: number of bytes the size-aware cache can hold. Usually the same as the flash cache size.
+ */
+func RunBelady(objCapacity int64, byteCapacity int64) {
+	minOHR, minBHR = simulateOPT(objCapacity, false)
+	sizeOptOHR, sizeOptBHR = simulateOPT(byteCapacity, true)
+	beladyDone = true
+	DFmtPrintf("RunBelady:: MIN OHR: %f, BHR: %f. Size-aware OPT OHR: %f, BHR: %f.\n",
+		minOHR, minBHR, sizeOptOHR, sizeOptBHR)
+}
+
+/**
+	Simulate furthest-in-future eviction. If 'bytes' is true, capacity is in bytes. Otherwise, it is in objects.
+	Stale heap entries (the object was requested again or evicted) are skipped lazily.
+	Same as the online caches, a request with a different size than the cached copy is a miss, and the cached copy
+	is replaced.
+ */
+func simulateOPT(capacity int64, bytes bool) (float64, float64) {
+	cached := make(map[int32]optEntry)		// object index --> valid heap entry
+	h := &optHeap{}
+	var used, optHits, optHitBytes int64
+	cost := func(size int64) int64 {
+		if bytes {
+			return size
+		}
+		return 1
+	}
+
+	for t, object := range optObjects {
+		size := optSizes[t]
+		next := optNext[t]
+
+		if entry, ok := cached[object]; ok {
+			if entry.size == size {
+				optHits++
+				optHitBytes += size
+				if next == noNextAccess {
+					// never requested again --> drop it right away
+					delete(cached, object)
+					used -= cost(size)
+				} else {
+					cached[object] = optEntry{object, next, size}
+					heap.Push(h, &optEntry{object, next, size})
+				}
+				continue
+			}
+			// out of date --> the cached copy is dropped, and the request is a miss
+			delete(cached, object)
+			used -= cost(entry.size)
+		}
+
+		if next == noNextAccess || cost(size) > capacity {
+			continue
+		}
+		cached[object] = optEntry{object, next, size}
+		heap.Push(h, &optEntry{object, next, size})
+		used += cost(size)
+
+		for used > capacity {
+			victim := heap.Pop(h).(*optEntry)
+			if curr, ok := cached[victim.object]; !ok || curr.next != victim.next {
+				continue
+			}
+			delete(cached, victim.object)
+			used -= cost(victim.size)
+		}
+	}
+
+	if len(optObjects) == 0 {
+		return 0, 0
+	}
+	return float64(optHits) / float64(len(optObjects)), float64(optHitBytes) / float64(optReqBytes)
+}
+
+/**
+	Return oracle results: MIN OHR, MIN BHR, size-aware OPT OHR and size-aware OPT BHR.
+ */
+func GetBeladyResults() (float64, float64, float64, float64) {
+	return minOHR, minBHR, sizeOptOHR, sizeOptBHR
+}
+
+func printBeladyResults() {
+	if beladyDone {
+		fmt.Printf("Belady MIN OHR: %f, BHR: %f. Size-aware OPT OHR: %f, BHR: %f.\n",
+			minOHR, minBHR, sizeOptOHR, sizeOptBHR)
+	}
+}
@@ -0,0 +1,63 @@
+package ObjectBased
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/**
+	Write a trace of "id size" requests in the simulator format and return its path.
+ */
+func writeTrace(t *testing.T, requests []string) string {
+	t.Helper()
+	lines := make([]string, len(requests))
+	for index, request := range requests {
+		lines[index] = "0 " + request
+	}
+	path := filepath.Join(t.TempDir(), "trace.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n") + "\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBeladyCyclic(t *testing.T) {
+	// LRU never hits on this loop, MIN keeps a and b and hits 4 times
+	BeladySetUp(writeTrace(t, []string{"a 1", "b 1", "c 1", "a 1", "b 1", "c 1", "a 1", "b 1", "c 1"}))
+	RunBelady(2, 2)
+	minOHR, _, sizeOHR, _ := GetBeladyResults()
+	if math.Abs(minOHR - 4.0 / 9) > 1e-9 || math.Abs(sizeOHR - 4.0 / 9) > 1e-9 {
+		t.Fatalf("MIN OHR %f and size-aware OHR %f, expected 4/9", minOHR, sizeOHR)
+	}
+}
+
+func TestBeladySizeAware(t *testing.T) {
+	// with 3 bytes, the large object is requested last, so it gives way to the two small ones
+	BeladySetUp(writeTrace(t, []string{"big 3", "s1 1", "s2 1", "s1 1", "s2 1", "big 3"}))
+	RunBelady(1, 3)
+	_, _, sizeOHR, sizeBHR := GetBeladyResults()
+	if math.Abs(sizeOHR - 2.0 / 6) > 1e-9 || math.Abs(sizeBHR - 2.0 / 10) > 1e-9 {
+		t.Fatalf("size-aware OHR %f and BHR %f, expected 1/3 and 1/5", sizeOHR, sizeBHR)
+	}
+}
+
+func TestBeladySizeChange(t *testing.T) {
+	// the 5 byte version of a never fits into 2 bytes, so nothing can be a hit
+	BeladySetUp(writeTrace(t, []string{"a 1", "a 5", "a 5"}))
+	RunBelady(1, 2)
+	minOHR, _, sizeOHR, sizeBHR := GetBeladyResults()
+	if minOHR != 1.0 / 3 || sizeOHR != 0 || sizeBHR != 0 {
+		t.Fatalf("MIN OHR %f, size-aware OHR %f and BHR %f, expected 1/3, 0 and 0", minOHR, sizeOHR, sizeBHR)
+	}
+
+	// the new version replaces the old one and is hit afterwards
+	BeladySetUp(writeTrace(t, []string{"a 1", "a 2", "b 1", "a 2", "b 1"}))
+	RunBelady(2, 3)
+	_, _, sizeOHR, sizeBHR = GetBeladyResults()
+	if math.Abs(sizeOHR - 2.0 / 5) > 1e-9 || math.Abs(sizeBHR - 3.0 / 7) > 1e-9 {
+		t.Fatalf("size-aware OHR %f and BHR %f, expected 2/5 and 3/7", sizeOHR, sizeBHR)
+	}
+}
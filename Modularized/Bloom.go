@@ -0,0 +1,103 @@
+package ObjectBased
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+/**
+	Bloom filter with k hash functions derived from one 64-bit FNV hash (double hashing).
+ */
+type BloomFilter struct {
+	bits		[]uint64
+	m			uint64		// number of bits
+	k			int			// number of hash functions
+}
+
+/**
+	Create a Bloom filter for n keys with the given false positive rate.
+ */
+func newBloomFilter(n int, fpRate float64) *BloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(float64(n) * bloomBitsPerObject(fpRate)))
+	if m < 64 {
+		m = 64
+	}
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &BloomFilter{
+		bits:	make([]uint64, (m + 63) / 64),
+		m:		m,
+		k:		k,
+	}
+}
+
+func bloomHash(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	return sum, (sum >> 32) | 1
+}
+
+func (filter *BloomFilter) add(key string) {
+	h1, h2 := bloomHash(key)
+	for i := 0; i < filter.k; i++ {
+		bit := (h1 + uint64(i) * h2) % filter.m
+		filter.bits[bit / 64] |= 1 << (bit % 64)
+	}
+}
+
+func (filter *BloomFilter) contains(key string) bool {
+	h1, h2 := bloomHash(key)
+	for i := 0; i < filter.k; i++ {
+		bit := (h1 + uint64(i) * h2) % filter.m
+		if filter.bits[bit / 64] & (1 << (bit % 64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+	Counting Bloom filter with 8-bit saturating counters. The count of a key is the minimum of its counters.
+ */
+type CountingBloomFilter struct {
+	counters	[]uint8
+	m			uint64
+	k			int
+}
+
+func newCountingBloomFilter(n int, fpRate float64) *CountingBloomFilter {
+	filter := newBloomFilter(n, fpRate)
+	return &CountingBloomFilter{
+		counters:	make([]uint8, filter.m),
+		m:			filter.m,
+		k:			filter.k,
+	}
+}
+
+func (filter *CountingBloomFilter) increment(key string) {
+	h1, h2 := bloomHash(key)
+	for i := 0; i < filter.k; i++ {
+		slot := (h1 + uint64(i) * h2) % filter.m
+		if filter.counters[slot] < math.MaxUint8 {
+			filter.counters[slot]++
+		}
+	}
+}
+
+func (filter *CountingBloomFilter) count(key string) int {
+	h1, h2 := bloomHash(key)
+	result := math.MaxUint8
+	for i := 0; i < filter.k; i++ {
+		slot := (h1 + uint64(i) * h2) % filter.m
+		if int(filter.counters[slot]) < result {
+			result = int(filter.counters[slot])
+		}
+	}
+	return result
+}
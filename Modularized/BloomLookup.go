@@ -0,0 +1,124 @@
+package ObjectBased
+
+import (
+	"container/list"
+	"fmt"
+	"log"
+)
+
+/**
+	Lookup of sealed boxes.
+	1. map: the exact global index cachedObj (default).
+	2. bloom: no global index. Every sealed box builds a Bloom filter of its objects when it is sealed.
+	   A lookup probes the filters of the sealed boxes in the object's size class, newest first, and reads the
+	   box from flash on every positive. A positive without the object is a false positive flash read.
+	   After the size classes change, sealed boxes keep their old upper bounds, so every class the object
+	   belonged to, and which still has sealed boxes, is probed (see liveGranularity).
+	cachedObj is still maintained in bloom mode, but only for bookkeeping, never for lookups.
+ */
+
+var (
+	lookupMode			= "map"
+	sealedByBound		map[int64]*list.List		// upper bound --> sealed boxes, newest in the front
+	sealedClassPos		map[int64]*list.Element		// box id --> position in sealedByBound
+
+	/* experiment part */
+	bloomLookups		int64
+	bloomProbes			int64		// number of filters probed
+	flashReads			int64		// number of boxes read because of a positive
+	falsePositiveReads	int64
+)
+
+/**
+	Choose how sealed boxes are looked up. Should be called before StartUp.
+ */
+func SetLookupMode(mode string) {
+	if mode != "map" && mode != "bloom" {
+		log.Fatalf("Wrong lookup mode %s. Should be map or bloom.\n", mode)
+	}
+	lookupMode = mode
+}
+
+func lookupSetUp() {
+	sealedByBound = make(map[int64]*list.List)
+	sealedClassPos = make(map[int64]*list.Element)
+	bloomLookups = 0
+	bloomProbes = 0
+	flashReads = 0
+	falsePositiveReads = 0
+}
+
+/**
+	Build the Bloom filter of a box which is being sealed.
+ */
+func sealBoxFilter(box *Box) {
+	if lookupMode != "bloom" {
+		return
+	}
+	box.filter = newBloomFilter(len(box.objOffsetMap), bloomFPRate)
+	for key := range box.objOffsetMap {
+		box.filter.add(key)
+	}
+	boxes, ok := sealedByBound[box.upperBound]
+	if !ok {
+		boxes = list.New()
+		sealedByBound[box.upperBound] = boxes
+	}
+	sealedClassPos[box.boxId] = boxes.PushFront(box)
+}
+
+func evictBoxFilter(box *Box) {
+	element, ok := sealedClassPos[box.boxId]
+	if !ok {
+		return
+	}
+	sealedByBound[box.upperBound].Remove(element)
+	delete(sealedClassPos, box.boxId)
+}
+
+/**
+	Find the sealed box holding the object. Return the box id and whether the object is cached.
+ */
+func lookupSealed(id string, objectSize int64) (int64, bool) {
+	if lookupMode != "bloom" {
+		boxId, ok := cachedObj[id]
+		return boxId, ok
+	}
+
+	bloomLookups++
+	for _, bound := range historicalBounds(objectSize) {
+		boxes, ok := sealedByBound[bound]
+		if !ok {
+			continue
+		}
+		for element := boxes.Front(); element != nil; element = element.Next() {
+			box := element.Value.(*Box)
+			bloomProbes++
+			if !box.filter.contains(id) {
+				continue
+			}
+			flashReads++
+			if _, ok := box.objOffsetMap[id]; ok {
+				return box.boxId, true
+			}
+			falsePositiveReads++
+		}
+	}
+	return 0, false
+}
+
+/**
+	Return lookup results.
+	1. Probes per lookup: average number of Bloom filters probed
+	2. False positive flash reads
+	3. Flash reads: all boxes read because of a positive
+ */
+func GetLookupResults() (float64, int64, int64) {
+	probes := 0.0
+	if bloomLookups > 0 {
+		probes = float64(bloomProbes) / float64(bloomLookups)
+	}
+	fmt.Printf("Lookup mode: %s, lookups: %d, probes per lookup: %f, flash reads: %d, false positive reads: %d.\n",
+		lookupMode, bloomLookups, probes, flashReads, falsePositiveReads)
+	return probes, falsePositiveReads, flashReads
+}
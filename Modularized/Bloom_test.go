@@ -0,0 +1,68 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	filter := newBloomFilter(1000, 0.01)
+	for key := 0; key < 1000; key++ {
+		filter.add("in" + strconv.Itoa(key))
+	}
+	for key := 0; key < 1000; key++ {
+		if !filter.contains("in" + strconv.Itoa(key)) {
+			t.Fatalf("false negative for key %d", key)
+		}
+	}
+	positives := 0
+	for key := 0; key < 10000; key++ {
+		if filter.contains("out" + strconv.Itoa(key)) {
+			positives++
+		}
+	}
+	if positives > 200 {
+		t.Fatalf("%d false positives of 10000, expected about 100", positives)
+	}
+}
+
+func TestCountingBloomFilter(t *testing.T) {
+	filter := newCountingBloomFilter(100, 0.01)
+	for count := 1; count <= 300; count++ {
+		filter.increment("a")
+		if count <= 3 {
+			filter.increment("b")
+		}
+	}
+	if filter.count("a") != 255 || filter.count("b") < 3 || filter.count("c") > 0 {
+		t.Fatalf("counts %d, %d and %d, expected 255, 3 and 0", filter.count("a"), filter.count("b"), filter.count("c"))
+	}
+}
+
+func TestBloomLookup(t *testing.T) {
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	replay("lameDuck", 30000)
+	mapHits := hits
+
+	SetLookupMode("bloom")
+	defer SetLookupMode("map")
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	replay("lameDuck", 30000)
+	if hits != mapHits {
+		t.Fatalf("%d hits with bloom lookup, %d with map lookup", hits, mapHits)
+	}
+	for _, pos := range boxQueueMap {
+		box := pos.element.Value.(*Box)
+		for key := range box.objOffsetMap {
+			if !box.filter.contains(key) {
+				t.Fatalf("filter of box %d misses %s", box.boxId, key)
+			}
+		}
+	}
+	probes, falsePositives, reads := GetLookupResults()
+	if probes < 1 || reads <= falsePositives || float64(falsePositives) > 0.05 * float64(bloomProbes) {
+		t.Fatalf("%f probes per lookup, %d flash reads, %d false positive reads", probes, reads, falsePositives)
+	}
+}
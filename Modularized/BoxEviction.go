@@ -0,0 +1,240 @@
+package ObjectBased
+
+import (
+	"container/list"
+	"log"
+)
+
+/**
+	Eviction order of sealed boxes.
+	1. S2LRU: hot and cold LRU queues, each holds half of the cache (default).
+	2. SIEVE: one FIFO queue in write order. A hit only marks the box, a hand sweeps from the oldest box
+	   and evicts the first box which was not hit since the hand last passed it.
+	3. S3FIFO: small probationary FIFO (10% of the cache) and main FIFO. A box leaving the small queue moves to
+	   the main queue if it was hit, otherwise it is evicted. A box leaving the main queue is reinserted
+	   at the end of the main queue with a lower frequency if it was hit.
+	4. CLOCK: boxes stay in physical write order and never move. A hit only increases the reference counter
+	   of the box (up to clockMaxCount). The hand sweeps over the boxes, decreasing non-zero counters,
+	   and evicts the first box with a zero counter. The new box is written into the freed slot,
+	   i.e. right behind the hand.
+	5. PARTITION: every size class has its own LRU queue and share of the flash, see Partition.go.
+	For SIEVE, S3FIFO and CLOCK, boxQueueMap still maps box id --> position, 'hot' means the box is in the main queue.
+	There is no ghost queue for boxes, since a box is never written again after it is evicted.
+ */
+
+const maxBoxFreq = 3
+
+var (
+	boxEviction		= "S2LRU"
+	fifoQueue		*list.List		// SIEVE, CLOCK: all sealed boxes. S3FIFO: main queue. Oldest in the front.
+	fifoSize		int64
+	smallQueue		*list.List		// S3FIFO: small queue
+	smallSize		int64
+	boxHand			*list.Element	// SIEVE, CLOCK: next candidate for eviction, nil --> front of the queue
+	clockMaxCount	= 1				// CLOCK: 1 --> reference bit, larger --> counter
+)
+
+/**
+	Choose the eviction policy of sealed boxes. Should be called before StartUp.
+ */
+func SetBoxEviction(policy string) {
+	switch policy {
+	case "S2LRU", "SIEVE", "S3FIFO", "CLOCK", "PARTITION":
+		boxEviction = policy
+	default:
+		log.Fatalf("Wrong box eviction policy %s. Should be S2LRU, SIEVE, S3FIFO, CLOCK or PARTITION.\n", policy)
+	}
+}
+
+/**
+	Set the maximum value of the CLOCK reference counter. 1 is the classic second-chance bit.
+ */
+func SetClockCounter(max int) {
+	if max < 1 {
+		log.Fatalf("CLOCK counter should be at least 1, got %d.\n", max)
+	}
+	clockMaxCount = max
+}
+
+func boxEvictionSetUp() {
+	fifoQueue = list.New()
+	fifoSize = 0
+	smallQueue = list.New()
+	smallSize = 0
+	boxHand = nil
+	partitionSetUp()
+}
+
+/**
+	Total flash capacity. maxCacheSize is the size of one S2LRU queue.
+ */
+func flashCapacity() int64 {
+	return 2 * maxCacheSize
+}
+
+/**
+	Add a newly sealed box into flash.
+ */
+func insertSealedBox(box *Box) {
+	switch boxEviction {
+	case "SIEVE":
+		for fifoSize + box.maxSize > flashCapacity() && fifoQueue.Len() > 0 {
+			sieveEvict()
+		}
+		pushToFifo(box, fifoQueue, true)
+	case "S3FIFO":
+		for smallSize + fifoSize + box.maxSize > flashCapacity() && smallQueue.Len() + fifoQueue.Len() > 0 {
+			s3fifoEvict()
+		}
+		pushToFifo(box, smallQueue, false)
+	case "CLOCK":
+		for fifoSize + box.maxSize > flashCapacity() && fifoQueue.Len() > 0 {
+			clockEvict()
+		}
+		clockInsert(box)
+	case "PARTITION":
+		partitionInsert(box)
+	default:
+		updateColdQueue(box)
+	}
+}
+
+/**
+	One object in a sealed box is hit.
+ */
+func hitSealedBox(boxId int64) {
+	switch boxEviction {
+	case "SIEVE":
+		boxQueueMap[boxId].element.Value.(*Box).freq = 1
+	case "S3FIFO":
+		box := boxQueueMap[boxId].element.Value.(*Box)
+		if box.freq < maxBoxFreq {
+			box.freq++
+		}
+	case "CLOCK":
+		box := boxQueueMap[boxId].element.Value.(*Box)
+		if box.freq < clockMaxCount {
+			box.freq++
+		}
+	case "PARTITION":
+		partitionHit(boxId)
+	default:
+		hitS2LRU(boxId)
+	}
+}
+
+func pushToFifo(box *Box, queue *list.List, main bool) {
+	queue.PushBack(box)
+	if main {
+		fifoSize += box.maxSize
+	} else {
+		smallSize += box.maxSize
+	}
+	boxQueueMap[box.boxId] = &QueuePos{queue.Back(), main}
+}
+
+func removeFromFifo(element *list.Element, main bool) {
+	if main {
+		if boxHand == element {
+			boxHand = element.Next()
+		}
+		fifoQueue.Remove(element)
+		fifoSize -= element.Value.(*Box).maxSize
+	} else {
+		smallQueue.Remove(element)
+		smallSize -= element.Value.(*Box).maxSize
+	}
+}
+
+/**
+	Box leaves flash: remove its objects from the index.
+ */
+func evictBox(box *Box) {
+	classEvict(box)
+	granularityEvict(box)
+	removeObjects(box)
+	evictBoxFilter(box)
+	delete(boxQueueMap, box.boxId)
+}
+
+func sieveEvict() {
+	hand := boxHand
+	if hand == nil {
+		hand = fifoQueue.Front()
+	}
+	for hand.Value.(*Box).freq > 0 {
+		hand.Value.(*Box).freq = 0
+		hand = hand.Next()
+		if hand == nil {
+			hand = fifoQueue.Front()
+		}
+	}
+	boxHand = hand
+	box := hand.Value.(*Box)
+	removeFromFifo(hand, true)
+	evictBox(box)
+}
+
+func s3fifoEvict() {
+	if smallSize >= flashCapacity() / 10 || fifoQueue.Len() == 0 {
+		// small queue is over its share --> the oldest box in small queue leaves
+		element := smallQueue.Front()
+		box := element.Value.(*Box)
+		removeFromFifo(element, false)
+		if box.freq > 0 {
+			box.freq = 0
+			pushToFifo(box, fifoQueue, true)
+		} else {
+			evictBox(box)
+		}
+		return
+	}
+
+	for {
+		element := fifoQueue.Front()
+		box := element.Value.(*Box)
+		if box.freq > 0 {
+			box.freq--
+			fifoQueue.MoveToBack(element)
+			continue
+		}
+		removeFromFifo(element, true)
+		evictBox(box)
+		return
+	}
+}
+
+/**
+	Sweep the hand until a box with zero reference counter is found and evict it.
+	The hand stops at the box after the victim.
+ */
+func clockEvict() {
+	hand := boxHand
+	if hand == nil {
+		hand = fifoQueue.Front()
+	}
+	for hand.Value.(*Box).freq > 0 {
+		hand.Value.(*Box).freq--
+		hand = hand.Next()
+		if hand == nil {
+			hand = fifoQueue.Front()
+		}
+	}
+	boxHand = hand
+	box := hand.Value.(*Box)
+	removeFromFifo(hand, true)
+	evictBox(box)
+}
+
+/**
+	Write a sealed box into the slot right behind the hand, so the hand reaches it last.
+ */
+func clockInsert(box *Box) {
+	if boxHand == nil {
+		pushToFifo(box, fifoQueue, true)
+		return
+	}
+	fifoQueue.InsertBefore(box, boxHand)
+	fifoSize += box.maxSize
+	boxQueueMap[box.boxId] = &QueuePos{boxHand.Prev(), true}
+}
@@ -0,0 +1,79 @@
+package ObjectBased
+
+import "testing"
+
+/**
+	Seal n empty boxes of a quarter of the flash each and return them.
+ */
+func sealQuarterBoxes(first int64, n int) []*Box {
+	boxes := make([]*Box, n)
+	for index := range boxes {
+		boxes[index] = &Box{boxId: first + int64(index), upperBound: granularity[0], maxSize: flashCapacity() / 4}
+		insertSealedBox(boxes[index])
+	}
+	return boxes
+}
+
+func TestSieveBoxEviction(t *testing.T) {
+	SetBoxEviction("SIEVE")
+	defer SetBoxEviction("S2LRU")
+	testStartUp(t)
+	sealQuarterBoxes(0, 4)
+	hitSealedBox(0)
+	// the hand passes box 0 and evicts box 1, then box 2
+	sealQuarterBoxes(4, 2)
+	for boxId, cached := range map[int64]bool{0: true, 1: false, 2: false, 3: true, 4: true, 5: true} {
+		if _, ok := boxQueueMap[boxId]; ok != cached {
+			t.Fatalf("box %d in flash: %t, expected %t", boxId, ok, cached)
+		}
+	}
+	if fifoSize != flashCapacity() {
+		t.Fatalf("%d of %d bytes in use", fifoSize, flashCapacity())
+	}
+}
+
+func TestS3FifoBoxEviction(t *testing.T) {
+	SetBoxEviction("S3FIFO")
+	defer SetBoxEviction("S2LRU")
+	testStartUp(t)
+	sealQuarterBoxes(0, 4)
+	hitSealedBox(0)
+	// box 0 was hit and moves to main queue, box 1 leaves flash
+	sealQuarterBoxes(4, 1)
+	if pos, ok := boxQueueMap[0]; !ok || !pos.hot {
+		t.Fatalf("box 0 is not in main queue")
+	}
+	if _, ok := boxQueueMap[1]; ok {
+		t.Fatalf("box 1 was not hit, but is still in flash")
+	}
+	if smallSize + fifoSize != flashCapacity() || fifoSize != flashCapacity() / 4 {
+		t.Fatalf("small queue %d bytes, main queue %d bytes", smallSize, fifoSize)
+	}
+}
+
+func TestClockBoxEviction(t *testing.T) {
+	SetBoxEviction("CLOCK")
+	SetClockCounter(2)
+	defer SetBoxEviction("S2LRU")
+	defer SetClockCounter(1)
+	testStartUp(t)
+	sealQuarterBoxes(0, 4)
+	hitSealedBox(0)
+	hitSealedBox(0)
+	hitSealedBox(1)
+	// the hand decreases the counters of boxes 0 and 1, evicts box 2 and box 4 is written into its slot
+	sealQuarterBoxes(4, 1)
+	// box 3 is next, then the hand wraps around and box 1 has no references left
+	sealQuarterBoxes(5, 2)
+	var order []int64
+	for element := fifoQueue.Front(); element != nil; element = element.Next() {
+		order = append(order, element.Value.(*Box).boxId)
+	}
+	expected := []int64{0, 6, 4, 5}
+	if !sameBounds(order, expected) {
+		t.Fatalf("boxes in flash: %v, expected %v", order, expected)
+	}
+	if freq := boxQueueMap[0].element.Value.(*Box).freq; freq != 0 {
+		t.Fatalf("box 0 has counter %d, expected 0", freq)
+	}
+}
@@ -0,0 +1,30 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestClassBoxSizes(t *testing.T) {
+	defer SetClassBoxSizes(nil)
+	SetClassBoxSizes([]int64{4096, 0, 1 << 16})
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	expected := map[int64]int64{32: 4096, 1024: 1 << 20, 32768: 1 << 16, testMaxObjSize: 1 << 20}
+	for bound, size := range expected {
+		if openBoxes[bound].maxSize != size {
+			t.Fatalf("class %d has boxes of %d bytes, expected %d", bound, openBoxes[bound].maxSize, size)
+		}
+	}
+
+	// 128 objects fill a 4 KB box exactly, 3 objects leave 5536 bytes of a 64 KB box unused
+	for id := 0; id <= 128; id++ {
+		Request("s" + strconv.Itoa(id), "32", "lameDuck")
+	}
+	for id := 0; id <= 3; id++ {
+		Request("m" + strconv.Itoa(id), "20000", "lameDuck")
+	}
+	if numSeal != 2 || sealedBytes != 4096 + 1 << 16 || fragBytes != 5536 {
+		t.Fatalf("%d seals of %d bytes, %d bytes unused", numSeal, sealedBytes, fragBytes)
+	}
+}
@@ -0,0 +1,67 @@
+package ObjectBased
+
+import (
+	"awesomeProject/Chunk"
+	"fmt"
+)
+
+/**
+	Objects which do not fit any size class. By default they bypass the cache and count as misses.
+	With chunking, they are split into chunks of chunkSize bytes cached as independent entries "id#chunkN".
+	If chunkAll is set, every object larger than one chunk is chunked, not only the oversized ones.
+	A chunked request is a hit only if every chunk is a hit, and hit bytes are the requested bytes served from
+	chunks in cache. If some chunks are hit, it is a partial hit.
+	rangeStats keeps the partial-hit accounting of every request: bytes served from cache and bytes fetched
+	from origin.
+ */
+
+var (
+	chunkSize			int64		// 0 --> no chunking
+	chunkAll			bool
+
+	/* experiment part */
+	oversizedRequests	int64		// requests bypassing the cache
+	oversizedBytes		int64
+	rangeStats			Chunk.Stats
+)
+
+/**
+	Split objects into chunks of the given size (Bytes). 0 --> oversized objects bypass the cache.
+	If all is false, only objects which do not fit any size class are chunked. Should be called after StartUp.
+ */
+func ChunkSetUp(size int64, all bool) {
+	chunkSize = size
+	chunkAll = all
+}
+
+func chunked(objectSize int64) bool {
+	return chunkSize > 0 && (oversized(objectSize) || (chunkAll && objectSize > chunkSize))
+}
+
+/**
+	Request the chunks covering bytes [start, end] of the object. Return true if all of them are hits.
+ */
+func chunkedRequest(id string, objectSize int64, start int64, end int64, model string) bool {
+	if oversized(chunkSize) {
+		DPrintf("Chunk size %d exceeds the maximum box size.\n", chunkSize)
+		oversizedRequests++
+		oversizedBytes += objectSize
+		rangeStats.Record(false, objectSize, start, end)
+		return false
+	}
+
+	served := rangeStats.ServedBytes
+	hit := rangeStats.Serve(func(chunkId string, size int64) bool {
+		return flashLookup(chunkId, size, model)
+	}, id, objectSize, chunkSize, start, end)
+	hitBytes += rangeStats.ServedBytes - served
+	if hit {
+		hits++
+	}
+	return hit
+}
+
+func printChunkResults() {
+	fmt.Printf("oversized requests: %d, oversized bytes: %d. Range requests: %s.\n",
+		oversizedRequests, oversizedBytes, rangeStats.String())
+}
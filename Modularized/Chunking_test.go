@@ -0,0 +1,72 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestOversizedMiss(t *testing.T) {
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	big := strconv.Itoa(2 * testMaxObjSize)
+	Request("big", big, "lameDuck")
+	Request("big", big, "lameDuck")
+	if hits != 0 || oversizedRequests != 2 || oversizedBytes != 4 * testMaxObjSize || reqBytes != 4 * testMaxObjSize {
+		t.Fatalf("%d hits, %d oversized requests of %d bytes, %d requested bytes", hits, oversizedRequests, oversizedBytes, reqBytes)
+	}
+	if rangeStats.Requests != 2 || rangeStats.OriginBytes != 4 * testMaxObjSize {
+		t.Fatalf("range stats: %s", rangeStats.String())
+	}
+}
+
+func TestOversizedChunked(t *testing.T) {
+	defer ChunkSetUp(0, false)
+	for _, all := range []bool{false, true} {
+		testStartUp(t)
+		WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+		ChunkSetUp(testMaxObjSize / 2, all)
+		// three chunks, the last one is half a chunk
+		size := strconv.Itoa(testMaxObjSize * 5 / 4)
+		Request("big", size, "lameDuck")
+		Request("big", size, "lameDuck")
+		if hits != 1 || hitBytes != testMaxObjSize * 5 / 4 || oversizedRequests != 0 {
+			t.Fatalf("all %t: %d hits of %d bytes, %d oversized requests", all, hits, hitBytes, oversizedRequests)
+		}
+		if rangeStats.Chunks != 6 || rangeStats.ChunkHits != 3 {
+			t.Fatalf("all %t: range stats: %s", all, rangeStats.String())
+		}
+		// only chunked if all is set
+		Request("medium", strconv.Itoa(testMaxObjSize * 3 / 4), "lameDuck")
+		if chunks := rangeStats.Chunks - 6; (all && chunks != 2) || (!all && chunks != 0) {
+			t.Fatalf("all %t: object smaller than the largest class split into %d chunks", all, chunks)
+		}
+	}
+}
+
+func TestRangeRequest(t *testing.T) {
+	defer ChunkSetUp(0, false)
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	ChunkSetUp(testMaxObjSize / 4, false)
+	size := strconv.Itoa(2 * testMaxObjSize)
+	// the first quarter of the object is cached, the second request only hits its first chunk
+	RangeRequest("big", size, 0, testMaxObjSize / 4 - 1, "lameDuck")
+	RangeRequest("big", size, 0, testMaxObjSize / 2 - 1, "lameDuck")
+	if hits != 0 || hitBytes != testMaxObjSize / 4 || reqBytes != testMaxObjSize * 3 / 4 {
+		t.Fatalf("%d hits of %d bytes, %d requested bytes", hits, hitBytes, reqBytes)
+	}
+	if rangeStats.PartialHits != 1 || rangeStats.OriginBytes != testMaxObjSize / 2 {
+		t.Fatalf("range stats: %s", rangeStats.String())
+	}
+	RangeRequest("big", size, 100, 200, "lameDuck")
+	if hits != 1 || hitBytes != testMaxObjSize / 4 + 101 {
+		t.Fatalf("%d hits of %d bytes", hits, hitBytes)
+	}
+
+	// without chunking, a range of a cached object is served whole
+	Request("small", "1000", "lameDuck")
+	RangeRequest("small", "1000", 900, 2000, "lameDuck")
+	if hits != 2 || hitBytes != testMaxObjSize / 4 + 201 {
+		t.Fatalf("%d hits of %d bytes", hits, hitBytes)
+	}
+}
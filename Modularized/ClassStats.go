@@ -0,0 +1,136 @@
+package ObjectBased
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+/**
+	Statistics of one size class, keyed by its upper bound. After the size classes change, boxes sealed
+	with old bounds are still counted under their old bounds.
+	Requests are charged to the class of the object when they arrive, with the requested (range) bytes, so the
+	classes add up to the totals of GetResults. Requests which are not served by a size class have their own
+	buckets: kangarooClass for the Kangaroo tier, oversizedClass for objects bypassing the cache and chunkedClass
+	for objects split into chunks.
+ */
+type ClassStat struct {
+	UpperBound		int64
+	Requests		int64
+	Hits			int64
+	ReqBytes		int64
+	HitBytes		int64
+	Seals			int64
+	FillSum			float64		// sum of currSize / maxSize of sealed boxes
+	Evictions		int64
+	AgeSum			int64		// sum of requests between seal and eviction
+}
+
+const (
+	kangarooClass	int64 = 0
+	oversizedClass	int64 = -1
+	chunkedClass	int64 = -2
+)
+
+var classStats		map[int64]*ClassStat
+
+func classStatSetUp() {
+	classStats = make(map[int64]*ClassStat)
+}
+
+func classStat(bound int64) *ClassStat {
+	stat, ok := classStats[bound]
+	if !ok {
+		stat = &ClassStat{UpperBound: bound}
+		classStats[bound] = stat
+	}
+	return stat
+}
+
+/**
+	Class of a request for the object, in the same order as flashRequest serves it.
+ */
+func requestClass(objectSize int64) int64 {
+	switch {
+	case chunked(objectSize):
+		return chunkedClass
+	case oversized(objectSize):
+		return oversizedClass
+	case objectSize < kangarooThreshold:
+		return kangarooClass
+	}
+	return getBound(objectSize)
+}
+
+func classRecord(bound int64, bytes int64, hits int64, hitBytes int64) {
+	stat := classStat(bound)
+	stat.Requests++
+	stat.ReqBytes += bytes
+	stat.Hits += hits
+	stat.HitBytes += hitBytes
+}
+
+func classSeal(box *Box) {
+	stat := classStat(box.upperBound)
+	stat.Seals++
+	stat.FillSum += float64(box.currSize) / float64(box.maxSize)
+}
+
+func classEvict(box *Box) {
+	stat := classStat(box.upperBound)
+	stat.Evictions++
+	stat.AgeSum += numRequest - box.sealedAt
+}
+
+/**
+	Average fill of a box at seal time.
+ */
+func (stat *ClassStat) AvgFill() float64 {
+	if stat.Seals == 0 {
+		return 0
+	}
+	return stat.FillSum / float64(stat.Seals)
+}
+
+/**
+	Average number of requests between the seal and the eviction of a box.
+ */
+func (stat *ClassStat) AvgAge() float64 {
+	if stat.Evictions == 0 {
+		return 0
+	}
+	return float64(stat.AgeSum) / float64(stat.Evictions)
+}
+
+/**
+	Return the statistics of every size class, sorted by upper bound.
+ */
+func GetClassResults() []ClassStat {
+	result := make([]ClassStat, 0, len(classStats))
+	for _, stat := range classStats {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UpperBound < result[j].UpperBound })
+	return result
+}
+
+func printClassResults() {
+	fmt.Printf("%12s %10s %10s %14s %14s %8s %8s %10s %12s\n", "bound", "requests", "hits", "reqBytes",
+		"hitBytes", "seals", "avgFill", "evictions", "avgAge")
+	for _, stat := range GetClassResults() {
+		fmt.Printf("%12s %10d %10d %14d %14d %8d %8.4f %10d %12.1f\n", classLabel(stat.UpperBound), stat.Requests, stat.Hits,
+			stat.ReqBytes, stat.HitBytes, stat.Seals, stat.AvgFill(), stat.Evictions, stat.AvgAge())
+	}
+}
+
+func classLabel(bound int64) string {
+	switch bound {
+	case kangarooClass:
+		return "kangaroo"
+	case oversizedClass:
+		return "oversized"
+	case chunkedClass:
+		return "chunked"
+	}
+	return strconv.FormatInt(bound, 10)
+}
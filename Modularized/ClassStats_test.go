@@ -0,0 +1,95 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+/**
+	Check that the size classes add up to the totals.
+ */
+func checkClassTotals(t *testing.T) {
+	t.Helper()
+	var total ClassStat
+	results := GetClassResults()
+	for index, stat := range results {
+		if index > 0 && stat.UpperBound <= results[index - 1].UpperBound {
+			t.Fatalf("classes are not sorted by upper bound")
+		}
+		total.Requests += stat.Requests
+		total.Hits += stat.Hits
+		total.ReqBytes += stat.ReqBytes
+		total.HitBytes += stat.HitBytes
+		total.Seals += stat.Seals
+		total.Evictions += stat.Evictions
+	}
+	if total.Requests != numRequest || total.Hits != hits || total.ReqBytes != reqBytes || total.HitBytes != hitBytes {
+		t.Fatalf("classes count %d requests, %d hits, %d bytes and %d hit bytes, expected %d, %d, %d and %d",
+			total.Requests, total.Hits, total.ReqBytes, total.HitBytes, numRequest, hits, reqBytes, hitBytes)
+	}
+	if total.Seals != numSeal || total.Evictions != numSeal - int64(len(boxQueueMap)) {
+		t.Fatalf("classes count %d seals and %d evictions, %d seals and %d boxes in flash",
+			total.Seals, total.Evictions, numSeal, len(boxQueueMap))
+	}
+}
+
+func TestClassStatsAddUp(t *testing.T) {
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	replay("lameDuck", 30000)
+	checkClassTotals(t)
+	if numSeal == int64(len(boxQueueMap)) {
+		t.Fatalf("no box evicted")
+	}
+}
+
+func TestClassStatsOtherTiers(t *testing.T) {
+	defer func() { kangarooThreshold = 0 }()
+	defer ChunkSetUp(0, false)
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	KangarooSetUp(2000, 128 << 10, 64 << 10, 1 << 20, 4096, 1)
+	replay("lameDuck", 5000)
+	if classStat(kangarooClass).Requests == 0 {
+		t.Fatalf("no request charged to the Kangaroo tier")
+	}
+	checkClassTotals(t)
+
+	// one range hit of 100 bytes
+	stat := classStat(getBound(5000))
+	last := *stat
+	RangeRequest("r", "5000", 0, 4999, "lameDuck")
+	RangeRequest("r", "5000", 100, 199, "lameDuck")
+	if stat.HitBytes - last.HitBytes != 100 || stat.ReqBytes - last.ReqBytes != 5100 {
+		t.Fatalf("class %d counts %d of %d bytes, expected 100 of 5100", stat.UpperBound,
+			stat.HitBytes - last.HitBytes, stat.ReqBytes - last.ReqBytes)
+	}
+	Request("big", strconv.Itoa(2 * testMaxObjSize), "lameDuck")
+	ChunkSetUp(testMaxObjSize / 2, false)
+	Request("big", strconv.Itoa(2 * testMaxObjSize), "lameDuck")
+	if classStat(oversizedClass).Requests != 1 || classStat(chunkedClass).Requests != 1 {
+		t.Fatalf("oversized and chunked requests are not counted")
+	}
+	checkClassTotals(t)
+}
+
+func TestClassStatsFillAndAge(t *testing.T) {
+	defer SetClassBoxSizes(nil)
+	SetClassBoxSizes([]int64{4096})
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	// the first box is full, the second one holds 64 objects when it is flushed
+	for id := 0; id < 192; id++ {
+		Request(strconv.Itoa(id), "32", "lameDuck")
+	}
+	FlushOpenBoxes()
+	stat := classStat(32)
+	if stat.Seals != 2 || stat.AvgFill() != 0.75 || stat.AvgAge() != 0 {
+		t.Fatalf("%d seals, average fill %f, average age %f", stat.Seals, stat.AvgFill(), stat.AvgAge())
+	}
+	box := &Box{upperBound: 32, sealedAt: numRequest - 10}
+	classEvict(box)
+	if stat.Evictions != 1 || stat.AvgAge() != 10 {
+		t.Fatalf("%d evictions, average age %f", stat.Evictions, stat.AvgAge())
+	}
+}
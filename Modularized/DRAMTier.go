@@ -0,0 +1,115 @@
+package ObjectBased
+
+import (
+	"awesomeProject/Chunk"
+	"awesomeProject/LRU"
+	"log"
+)
+
+/**
+	DRAM object cache in front of the flash box cache. It reuses the object-level policies in the LRU package.
+	A request is first looked up in DRAM. DRAM misses go to the flash lookup. Objects fetched from the origin
+	are inserted into DRAM, objects hit in flash are only inserted (promoted) if dramPromote is set.
+ */
+
+var (
+	dramPolicy		string		// "S2LRU", "SIEVE" or "S3FIFO". Empty --> no DRAM tier
+	dramPromote		bool
+	dramSize		int64
+
+	/* experiment part */
+	dramHits		int64
+	dramHitBytes	int64
+	flashHits		int64
+	flashHitBytes	int64
+	flashRequests	int64		// requests which missed in DRAM
+)
+
+/**
+	Set up the DRAM tier with its size (Bytes) and policy. Should be called after StartUp.
+ */
+func DRAMSetUp(size int, policy string, promote bool) {
+	switch policy {
+	case "S2LRU":
+		LRU.LruCache(size)
+	case "SIEVE":
+		LRU.SieveCache(size)
+	case "S3FIFO":
+		LRU.S3FifoCache(size)
+	default:
+		log.Fatalf("Wrong DRAM policy %s. Should be S2LRU, SIEVE or S3FIFO.\n", policy)
+	}
+	dramPolicy = policy
+	dramSize = int64(size)
+	dramPromote = promote
+	dramHits = 0
+	dramHitBytes = 0
+	flashHits = 0
+	flashHitBytes = 0
+	flashRequests = 0
+}
+
+func dramContains(id string, size string) bool {
+	switch dramPolicy {
+	case "SIEVE":
+		return LRU.SieveContains(id, size)
+	case "S3FIFO":
+		return LRU.S3FifoContains(id, size)
+	default:
+		return LRU.S2LRUContains(id, size)
+	}
+}
+
+/**
+	Access the object in DRAM: update the policy on a hit, insert it on a miss.
+ */
+func dramAccess(id string, size string) {
+	switch dramPolicy {
+	case "SIEVE":
+		LRU.SieveRequest(id, size)
+	case "S3FIFO":
+		LRU.S3FifoRequest(id, size)
+	default:
+		LRU.S2LRURequest(id, size)
+	}
+}
+
+func dramRequest(id string, size string, objectSize int64, start int64, end int64, model string) {
+	bytes := Chunk.RangeBytes(objectSize, start, end)
+	if dramContains(id, size) {
+		dramAccess(id, size)
+		hits++
+		hitBytes += bytes
+		dramHits++
+		dramHitBytes += bytes
+		rangeStats.Record(true, objectSize, start, end)
+		return
+	}
+
+	flashRequests++
+	flashHit := flashRequest(id, objectSize, start, end, model)
+	if flashHit {
+		flashHits++
+		flashHitBytes += bytes
+	}
+	if !flashHit || dramPromote {
+		dramAccess(id, size)
+	}
+}
+
+/**
+	Return DRAM and flash results. Total OHR and BHR are returned by GetResults.
+	1. DRAM OHR: #DRAM hits / #requests
+	2. DRAM BHR: #DRAM hit bytes / #requested bytes
+	3. Flash OHR: #flash hits / #requests which missed in DRAM
+	4. Flash BHR: #flash hit bytes / #requested bytes
+ */
+func GetDRAMResults() (float64, float64, float64, float64) {
+	DFmtPrintf("DRAM:: policy: %s, promote: %t, DRAM hits: %d, flash hits: %d, flash requests: %d.\n",
+		dramPolicy, dramPromote, dramHits, flashHits, flashRequests)
+	dramOHR := float64(dramHits) / float64(numRequest)
+	dramBHR := float64(dramHitBytes) / float64(reqBytes)
+	flashOHR := float64(flashHits) / float64(flashRequests)
+	flashBHR := float64(flashHitBytes) / float64(reqBytes)
+	return dramOHR, dramBHR, flashOHR, flashBHR
+}
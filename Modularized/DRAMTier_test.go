@@ -0,0 +1,30 @@
+package ObjectBased
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDRAMTier(t *testing.T) {
+	defer func() { dramPolicy = "" }()
+	for _, promote := range []bool{false, true} {
+		testStartUp(t)
+		WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+		DRAMSetUp(1500, "SIEVE", promote)
+		Request("a", "1000", "lameDuck")
+		Request("a", "1000", "lameDuck")
+		// b pushes a out of DRAM, a is still in flash
+		Request("b", "1000", "lameDuck")
+		Request("a", "1000", "lameDuck")
+		if dramHits != 1 || flashHits != 1 || flashRequests != 3 || hits != 2 {
+			t.Fatalf("promote %t: %d DRAM hits, %d flash hits of %d, %d hits", promote, dramHits, flashHits, flashRequests, hits)
+		}
+		if dramContains("a", "1000") != promote {
+			t.Fatalf("promote %t: a in DRAM after a flash hit: %t", promote, !promote)
+		}
+		dramOHR, dramBHR, flashOHR, _ := GetDRAMResults()
+		if dramOHR != 0.25 || dramBHR != 0.25 || math.Abs(flashOHR - 1.0 / 3) > 1e-9 {
+			t.Fatalf("promote %t: DRAM OHR %f, BHR %f, flash OHR %f", promote, dramOHR, dramBHR, flashOHR)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package ObjectBased
 
 import (
-	"math/rand"
 	"strings"
 	"log"
 )
@@ -12,8 +11,7 @@ import (
  */
 
 var(
-	quotaFixed 			int64
-	erasureFixed		int64
+	fixedProbQuantum	int64		// last quantum of writeBudget seen by updateFixedProb
 	higherProb			float64
 	//lowerProb			float64
 	//interval			int
@@ -24,14 +22,13 @@ var(
 /**
 
  */
-func FixedProbSetUp(quota int64) {
-	quotaFixed = quota
+func FixedProbSetUp() {
 	//interval = 1
 	//budget = int64(interval) * quotaFixed
 	fixedProb = 1
 	higherProb = 1
 	//lowerProb = 0
-	erasureFixed = 0
+	fixedProbQuantum = 0
 }
 
 /**
@@ -43,14 +40,18 @@ func FixedProbSetUp(quota int64) {
 	2. SmilingTurtle
  */
 func updateFixedProb(method string) {
-	if numRequest % Epoch != 0 || numRequest < 250 * Epoch {
+	if writeBudget.quanta == fixedProbQuantum {
 		return
 	}
-	var budget int64
+	fixedProbQuantum = writeBudget.quanta
+	// whiteBear: bytes written in the last quantum. smilingTurtle: bytes written in all quanta.
+	var budget, erasureFixed int64
 	if strings.Compare(method, "whiteBear") == 0 {
-		budget = quotaFixed
+		budget = writeBudget.Refill()
+		erasureFixed = writeBudget.lastUsed
 	} else if strings.Compare(method, "smilingTurtle") == 0 {
-		budget = (numRequest / Epoch - 249) * quotaFixed
+		budget = writeBudget.quanta * writeBudget.Refill()
+		erasureFixed = writeBudget.spent - writeBudget.Used()
 	} else {
 		log.Fatalf("Wrong method! Should be whiteBear or smilingTurtle.\n")
 	}
@@ -62,14 +63,11 @@ func updateFixedProb(method string) {
 	} else {
 		fixedProb = (higherProb + fixedProb) / 2
 	}
-	if strings.Compare(method, "whiteBear") == 0 {
-		erasureFixed = 0
-	}
-	DFmtPrintf("Updated prob: %f, erasure bytes: %d.\n", fixedProb, erasureFixed)
+	DFmtPrintf("Updated prob: %f.\n", fixedProb)
 }
 
 func warmUpFixedProb(method string, size int64) bool {
-	if numRequest < 250 * Epoch {
+	if numRequest < warmUpRequests {
 		return true
 	} else {
 		return admissionControlFixedProb(size)
@@ -77,9 +75,8 @@ func warmUpFixedProb(method string, size int64) bool {
 }
 
 func admissionControlFixedProb(size int64) bool {
-	random := rand.Float64()
+	random := run.random.Float64()
 	if random < fixedProb {
-		erasureFixed += size
 		return true
 	} else {
 		return false
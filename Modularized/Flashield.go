@@ -0,0 +1,216 @@
+package ObjectBased
+
+import (
+	"container/list"
+	"fmt"
+	"log"
+	"math"
+)
+
+/**
+	Flashield-style admission (model "flashield"), after Eisenman et al., NSDI 2019.
+	Objects are observed in a DRAM-resident ghost (an LRU of flashieldGhostSize objects) that keeps per-object
+	features: hits seen in the ghost, last and average inter-arrival time (in requests), size and size class.
+	Every request produces one sample with the current features. The sample is labeled 1 if the object is
+	requested again within flashieldHorizon requests, otherwise 0. An object evicted from the ghost before
+	that is labeled 0 as well. Labeled samples train a logistic regression
+	online with SGD. A missed object is admitted when its predicted flashiness is at least flashieldThreshold.
+	Until flashieldWarmUp samples are labeled, every miss is admitted.
+ */
+
+const flashieldFeatures = 7
+
+type flashieldEntry struct {
+	id				string
+	hits			int64
+	lastAccess		int64
+	avgInterval		float64			// exponential moving average of inter-arrival times
+	pending			*flashieldSample
+}
+
+type flashieldSample struct {
+	entry			*flashieldEntry
+	features		[flashieldFeatures]float64
+	createdAt		int64
+	predicted		float64
+}
+
+var (
+	flashieldGhostSize		int
+	flashieldHorizon		int64
+	flashieldThreshold		float64
+	flashieldRate			float64			// SGD learning rate
+	flashieldWarmUp			int64
+	flashieldWeights		[flashieldFeatures]float64
+	flashieldGhost			*list.List		// LRU in the front
+	flashieldIndex			map[string]*list.Element
+	flashieldPending		*list.List		// samples waiting for a label, oldest in the front
+
+	/* experiment part */
+	flashieldSamples		int64
+	flashieldPositives		int64
+	flashieldCorrect		int64			// labeled samples predicted right before training on them
+	flashieldAdmits			int64
+	flashieldRejects		int64
+)
+
+/**
+	Set up Flashield admission. Should be called after StartUp.
+	ghostSize: objects tracked in DRAM. horizon: requests within which a re-read counts as flashy.
+	threshold: minimum predicted flashiness to admit. rate: learning rate. warmUp: labeled samples before the
+	classifier is used.
+ */
+func FlashieldSetUp(ghostSize int, horizon int64, threshold float64, rate float64, warmUp int64) {
+	if ghostSize < 1 || horizon <= 0 {
+		log.Fatalf("Wrong Flashield configuration: ghost size %d, horizon %d.\n", ghostSize, horizon)
+	}
+	flashieldGhostSize = ghostSize
+	flashieldHorizon = horizon
+	flashieldThreshold = threshold
+	flashieldRate = rate
+	flashieldWarmUp = warmUp
+	flashieldWeights = [flashieldFeatures]float64{}
+	flashieldGhost = list.New()
+	flashieldIndex = make(map[string]*list.Element)
+	flashieldPending = list.New()
+
+	flashieldSamples = 0
+	flashieldPositives = 0
+	flashieldCorrect = 0
+	flashieldAdmits = 0
+	flashieldRejects = 0
+}
+
+/**
+	Observe one request: label the previous sample of the object and of expired objects, update the features
+	in the ghost and create a new sample.
+ */
+func flashieldObserve(id string, size int64) {
+	for flashieldPending.Len() > 0 {
+		sample := flashieldPending.Front().Value.(*flashieldSample)
+		if numRequest - sample.createdAt <= flashieldHorizon {
+			break
+		}
+		flashieldPending.Remove(flashieldPending.Front())
+		if sample.entry.pending == sample {
+			sample.entry.pending = nil
+			flashieldTrain(sample, 0)
+		}
+	}
+
+	var entry *flashieldEntry
+	if element, ok := flashieldIndex[id]; ok {
+		flashieldGhost.MoveToBack(element)
+		entry = element.Value.(*flashieldEntry)
+		if entry.pending != nil {
+			flashieldTrain(entry.pending, 1)
+			entry.pending = nil
+		}
+		interval := float64(numRequest - entry.lastAccess)
+		if entry.hits == 0 {
+			entry.avgInterval = interval
+		} else {
+			entry.avgInterval = 0.5 * entry.avgInterval + 0.5 * interval
+		}
+		entry.hits++
+	} else {
+		entry = &flashieldEntry{id: id}
+		flashieldIndex[id] = flashieldGhost.PushBack(entry)
+		for flashieldGhost.Len() > flashieldGhostSize {
+			front := flashieldGhost.Remove(flashieldGhost.Front()).(*flashieldEntry)
+			if front.pending != nil {
+				// the ghost forgets the object before its horizon: label it as not flashy
+				flashieldTrain(front.pending, 0)
+				front.pending = nil
+			}
+			delete(flashieldIndex, front.id)
+		}
+	}
+
+	sample := &flashieldSample{entry: entry, createdAt: numRequest}
+	sample.features = flashieldExtract(entry, size)
+	sample.predicted = flashieldPredict(sample.features)
+	entry.lastAccess = numRequest
+	entry.pending = sample
+	flashieldPending.PushBack(sample)
+}
+
+func flashieldExtract(entry *flashieldEntry, size int64) [flashieldFeatures]float64 {
+	var features [flashieldFeatures]float64
+	features[0] = 1
+	features[1] = math.Log2(1 + float64(entry.hits))
+	if entry.hits > 0 {
+		features[2] = math.Log10(1 + float64(numRequest - entry.lastAccess))
+		features[3] = math.Log10(1 + entry.avgInterval)
+	} else {
+		features[4] = 1
+	}
+	features[5] = math.Log10(1 + float64(size))
+	for index, bound := range granularity {
+		if bound >= size {
+			features[6] = float64(index + 1) / float64(len(granularity))
+			break
+		}
+	}
+	return features
+}
+
+func flashieldPredict(features [flashieldFeatures]float64) float64 {
+	z := 0.0
+	for index, value := range features {
+		z += flashieldWeights[index] * value
+	}
+	return 1 / (1 + math.Exp(-z))
+}
+
+/**
+	One SGD step of logistic regression on a labeled sample.
+ */
+func flashieldTrain(sample *flashieldSample, label float64) {
+	flashieldSamples++
+	if label == 1 {
+		flashieldPositives++
+	}
+	if (sample.predicted >= flashieldThreshold) == (label == 1) {
+		flashieldCorrect++
+	}
+	gradient := label - flashieldPredict(sample.features)
+	for index, value := range sample.features {
+		flashieldWeights[index] += flashieldRate * gradient * value
+	}
+}
+
+/**
+	Admit the missed object if its predicted flashiness is above the threshold.
+ */
+func admissionFlashield(id string) bool {
+	admit := true
+	if flashieldSamples >= flashieldWarmUp {
+		if element, ok := flashieldIndex[id]; ok && element.Value.(*flashieldEntry).pending != nil {
+			admit = element.Value.(*flashieldEntry).pending.predicted >= flashieldThreshold
+		}
+	}
+	if admit {
+		flashieldAdmits++
+	} else {
+		flashieldRejects++
+	}
+	return admit
+}
+
+/**
+	Return Flashield results: admission ratio, accuracy of the predictions and the learned weights.
+ */
+func GetFlashieldResults() (float64, float64, []float64) {
+	ratio, accuracy := 0.0, 0.0
+	if flashieldAdmits + flashieldRejects > 0 {
+		ratio = float64(flashieldAdmits) / float64(flashieldAdmits + flashieldRejects)
+	}
+	if flashieldSamples > 0 {
+		accuracy = float64(flashieldCorrect) / float64(flashieldSamples)
+	}
+	fmt.Printf("Flashield:: admits: %d, rejects: %d, admission ratio: %f, samples: %d, positives: %d, "+
+		"accuracy: %f, weights: %v.\n", flashieldAdmits, flashieldRejects, ratio, flashieldSamples,
+		flashieldPositives, accuracy, flashieldWeights)
+	return ratio, accuracy, flashieldWeights[:]
+}
@@ -0,0 +1,46 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFlashieldLabels(t *testing.T) {
+	testStartUp(t)
+	FlashieldSetUp(1, 1000, 0.5, 0.1, 0)
+	flashieldObserve("a", 100)
+	flashieldObserve("b", 100)		// evicts a before its horizon
+	if flashieldSamples != 1 || flashieldPositives != 0 {
+		t.Fatalf("after a ghost eviction: %d samples, %d positives", flashieldSamples, flashieldPositives)
+	}
+	flashieldObserve("b", 100)		// b is re-read
+	if flashieldSamples != 2 || flashieldPositives != 1 {
+		t.Fatalf("after a re-read: %d samples, %d positives", flashieldSamples, flashieldPositives)
+	}
+	numRequest += 2000
+	flashieldObserve("b", 100)		// the last sample of b expired
+	if flashieldSamples != 3 || flashieldPositives != 1 {
+		t.Fatalf("after expiry: %d samples, %d positives", flashieldSamples, flashieldPositives)
+	}
+}
+
+func TestFlashieldLearns(t *testing.T) {
+	testStartUp(t)
+	FlashieldSetUp(1000, 500, 0.5, 0.05, 1000)
+	// ten hot objects between objects that are never read again
+	for index := 0; index < 50000; index++ {
+		numRequest++
+		id := "cold" + strconv.Itoa(index)
+		if index % 2 == 0 {
+			id = "hot" + strconv.Itoa(index % 20)
+		}
+		flashieldObserve(id, 1000)
+	}
+	_, accuracy, _ := GetFlashieldResults()
+	if accuracy < 0.9 {
+		t.Fatalf("accuracy %f on a separable trace", accuracy)
+	}
+	if admissionFlashield("cold49999") || !admissionFlashield("hot0") {
+		t.Fatalf("cold object admitted or hot object rejected")
+	}
+}
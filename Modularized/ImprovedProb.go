@@ -3,83 +3,47 @@ package ObjectBased
 import (
 	"math"
 	"strings"
-	"log"
-	"math/rand"
 )
 
-var (
-	// update every quantum
-	E			int64 		// written bytes in this quantum --> real-time
-	balance		int64		// balance in all past quantum --> accumulative
-)
-
-func ProbSetUp(budget int64) {
-	//K = k;					// slack variable
-	balance = budget;		// budget
-	quota = budget;			// initial budget
-	E = 0;					// used writes
-}
-
 /**
-	Reset the erasure bytes when one quantum finishes.
+	Default write budget: budget bytes per quantum of Epoch requests, everything unused is carried over.
+	The used bytes of the current quantum and the allowance (balance) come from writeBudget.
  */
-func updateProb() {
-	if numRequest % Epoch == 0 {
-		DFmtPrintf("updateProb:: Requests: %d, erasure bytes during last quantum: %d.\n", numRequest, E)
-		E = 0;
-	}
-}
-
-/**
-	Update budget for current interval and reset the used bytes to 0 when one interval finishes.
- */
-func updateImprovedProb() {
-	if numRequest % Epoch == 0 && numRequest >= 250 * Epoch {
-		DFmtPrintf("updateImprovedProb:: Requests: %d, used bytes: %d, last balance: %d.\n", numRequest, E, balance)
-		balance += quota - E
-		E = 0
-		DFmtPrintf("updateImprovedProb:: current balance: %d.\n", balance)
-	}
+func ProbSetUp(budget int64) {
+	//K = k;					// slack variable
+	writeBudget = newTokenBucket(budget, budget, unlimited, unlimited, Epoch)
 }
 
 /**
 	Check whether current request is in warm up phase.
-	Warm up phase: there is no budget for the first 250 million requests.
+	Warm up phase: there is no budget for the first warmUpRequests requests.
 	Return true if current request is within the warm up phase. Otherwise, use the improved probability
-	admission control to determine whether this missed object is cached or not.
+	admission control with the curve resolved by AdmissionSetUp to determine whether this missed object is cached.
  */
-func warmUpImprovedProb(model string, size int64) bool {
-	if numRequest < 250 * Epoch {
+func warmUpImprovedProb(size int64) bool {
+	if numRequest < warmUpRequests {
 		return true
 	} else {
 		//updateImprovedProb()
-		return admissionControlImprovedProb(model, size)
+		return admissionControlImprovedProb(admissionCurve, size)
 	}
 }
 
 
 /**
 	Combine probability admission control with TIRE "penalty" across time.
+	curve is the admission curve of the model, see AdmissionCurve.go.
 */
-func admissionControlImprovedProb(model string, size int64) bool {
-	if E > balance {
+func admissionControlImprovedProb(curve AdmissionCurve, size int64) bool {
+	balance := writeBudget.Allowance()
+	if writeBudget.Remaining() < 0 || balance <= 0 {
 		return false
 	}
-	var prob float64
-	if strings.Compare(model, "lameDuck") == 0 {
-		prob = improvedLameDuck()
-	} else if strings.Compare(model, "angryBird") == 0 {
-		prob = improvedAngryBird()
-	} else {
-		log.Fatalf("Wrong choice of probability. Should be lameDuck or angryBird!")
-	}
+	prob := curve(writeBudget.Used(), balance)
 
-	random := rand.Float64()
+	random := run.random.Float64()
 	var admit bool
 	admit = random <= prob
-	if admit {
-		E += size
-	}
 	//DFmtPrintf("admissioControlImprovedProb:: prob: %f, random: %f, admit: %t.\n", prob, random, admit)
 	return admit
 }
@@ -101,12 +65,9 @@ func admissionControlProb(line string, size int64) bool {
 		prob = angryBird()
 	}
 
-	random := rand.Float64()
+	random := run.random.Float64()
 	var admit bool
 	admit = random <= prob
-	if admit {
-		E += size
-	}
 	//DFmtPrintf("admissionControlProb:: requests: %d, prob: %f, random: %f, admit: %t.\n", numRequest, prob, random, admit)
 	return admit
 }
@@ -115,20 +76,7 @@ func admissionControlProb(line string, size int64) bool {
 	Probability: line
  */
 func lameDuck() float64 {
-	prob := -1 / float64(int64(K) * quota) * float64(E) + 1;
-	return prob
-}
-
-/**
-	Improved probability: the budget varies with intervals --> line
- */
-func improvedLameDuck() float64 {
-	var prob float64
-	if balance <= 0 {
-		prob = 0
-	} else {
-		prob = -1 / float64(int64(K) * balance) * float64(E) + 1;
-	}
+	prob := -1 / float64(int64(K) * writeBudget.Refill()) * float64(writeBudget.Used()) + 1;
 	return prob
 }
 
@@ -136,7 +84,7 @@ func improvedLameDuck() float64 {
 	Probability: exponential
  */
 func spicyChicken() float64 {
-	prob := math.Exp(float64(-E) / float64(quota))
+	prob := math.Exp(float64(-writeBudget.Used()) / float64(writeBudget.Refill()))
 	return prob
 }
 
@@ -144,19 +92,8 @@ func spicyChicken() float64 {
 	Probability: logarithm
  */
 func angryBird() float64 {
-	prob := math.Log(float64(K + 1) - float64(E) / float64(quota)) / math.Log(5)
+	prob := math.Log(float64(K + 1) - float64(writeBudget.Used()) / float64(writeBudget.Refill())) / math.Log(5)
 	//prob := math.Log(float64(E - int64(K) * quota))
 	return prob
 }
 
-func improvedAngryBird() float64 {
-	var prob float64
-	if balance <= 0 {
-		prob = 0
-	} else {
-		prob = math.Log(float64(balance) - float64(E)) / math.Log(float64(balance))
-		//prob = math.Log(float64(K + 1) - float64(E) / float64(quota)) / math.Log(5)
-	}
-	return prob
-}
-
@@ -0,0 +1,120 @@
+package ObjectBased
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+/**
+	Estimate of the DRAM used to index the flash cache. Four index designs can be selected:
+	1. fullMap: what the simulator does. cachedObj maps the full key to a box id, and every sealed box keeps
+	   objOffsetMap (key --> offset). Both maps pay the Go map overhead per entry plus the key bytes.
+	2. hashedKey: one hash table entry per object holding an 8-byte key hash and an 8-byte location.
+	3. partialKey: buckets of small entries holding a partial-key tag and a box index, as in Kangaroo / Flashield.
+	4. bloom: no per-object index, every sealed box keeps a Bloom filter of its objects.
+	Every design also pays for boxQueueMap (one entry per sealed box) and the Kangaroo log index if used.
+ */
+
+const (
+	mapEntryOverhead	= 48		// string header, value, tophash and bucket overflow, amortized at load factor 6.5/8
+	hashedKeyEntry		= 16		// 8-byte key hash + 8-byte box id and offset
+	hashedKeyLoad		= 0.75
+	partialKeyEntry		= 6			// 2-byte tag + 4-byte box index
+	partialKeyLoad		= 0.9
+	partialKeyBucket	= 64		// bucket size in bytes, 8 bytes of each bucket are the header
+	boxEntryOverhead	= 64		// boxQueueMap entry, QueuePos and Box struct
+)
+
+var (
+	indexDesign			= "fullMap"
+	bloomFPRate			= 0.01
+)
+
+/**
+	Choose the index design used to estimate DRAM. fpRate is the false positive rate of the per-box Bloom filters.
+ */
+func SetIndexDesign(design string, fpRate float64) {
+	switch design {
+	case "fullMap", "hashedKey", "partialKey", "bloom":
+		indexDesign = design
+	default:
+		log.Fatalf("Wrong index design %s. Should be fullMap, hashedKey, partialKey or bloom.\n", design)
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		log.Fatalf("Bloom filter false positive rate should be in (0, 1), got %f.\n", fpRate)
+	}
+	bloomFPRate = fpRate
+}
+
+/**
+	Bits per object of a Bloom filter with the optimal number of hash functions.
+ */
+func bloomBitsPerObject(fpRate float64) float64 {
+	return -math.Log(fpRate) / (math.Ln2 * math.Ln2)
+}
+
+/**
+	Estimated index bytes for the given number of objects and total key bytes.
+ */
+func objectIndexMemory(objects int64, keyBytes int64) int64 {
+	switch indexDesign {
+	case "hashedKey":
+		return int64(float64(objects * hashedKeyEntry) / hashedKeyLoad)
+	case "partialKey":
+		perBucket := (partialKeyBucket - 8) / partialKeyEntry
+		buckets := int64(math.Ceil(float64(objects) / partialKeyLoad / float64(perBucket)))
+		return buckets * partialKeyBucket
+	case "bloom":
+		return int64(math.Ceil(float64(objects) * bloomBitsPerObject(bloomFPRate) / 8))
+	default:
+		return objects * mapEntryOverhead + keyBytes
+	}
+}
+
+/**
+	Current estimate of DRAM used by the index of the flash cache.
+ */
+func indexMemory() int64 {
+	objects := int64(len(cachedObj))
+	memory := objectIndexMemory(objects, cachedKeyBytes)
+	if indexDesign == "fullMap" {
+		// objOffsetMap in sealed boxes holds the same keys again
+		memory += objects * mapEntryOverhead + cachedKeyBytes
+	}
+	memory += int64(len(boxQueueMap)) * boxEntryOverhead
+
+	if kangarooThreshold > 0 {
+		var keyBytes int64
+		for key := range kLogIndex {
+			keyBytes += int64(len(key))
+		}
+		memory += objectIndexMemory(int64(len(kLogIndex)), keyBytes)
+	}
+	return memory
+}
+
+/**
+	Return index results.
+	1. Index memory: estimated DRAM used by the index (Bytes)
+	2. Bytes per cached object
+	3. DRAM budget: index memory plus the DRAM tier if there is one
+ */
+func GetIndexResults() (int64, float64, int64) {
+	memory := indexMemory()
+	objects := int64(len(cachedObj))
+	if kangarooThreshold > 0 {
+		objects += int64(len(kLogIndex))
+	}
+	perObject := 0.0
+	if objects > 0 {
+		perObject = float64(memory) / float64(objects)
+	}
+	budget := memory
+	if dramPolicy != "" {
+		budget += dramSize
+	}
+	fmt.Printf("Index design: %s, index memory: %d, bytes per object: %f, DRAM budget: %d, flash capacity: %d.\n",
+		indexDesign, memory, perObject, budget, flashCapacity())
+	return memory, perObject, budget
+}
@@ -0,0 +1,32 @@
+package ObjectBased
+
+import "testing"
+
+func TestObjectIndexMemory(t *testing.T) {
+	defer SetIndexDesign("fullMap", 0.01)
+	// 1000 objects with 8-byte keys
+	for design, expected := range map[string]int64{"fullMap": 56000, "hashedKey": 21333, "partialKey": 124 * 64, "bloom": 1199} {
+		SetIndexDesign(design, 0.01)
+		if memory := objectIndexMemory(1000, 8000); memory != expected {
+			t.Fatalf("%s: %d bytes, expected %d", design, memory, expected)
+		}
+	}
+}
+
+func TestIndexMemoryTracksCache(t *testing.T) {
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	replay("lameDuck", 30000)
+	var keyBytes int64
+	for key := range cachedObj {
+		keyBytes += int64(len(key))
+	}
+	if keyBytes != cachedKeyBytes {
+		t.Fatalf("cached keys hold %d bytes, accounted %d", keyBytes, cachedKeyBytes)
+	}
+	objects := int64(len(cachedObj))
+	expected := 2 * (objects * mapEntryOverhead + keyBytes) + int64(len(boxQueueMap)) * boxEntryOverhead
+	if memory, _, budget := GetIndexResults(); memory != expected || budget != memory {
+		t.Fatalf("index memory %d and DRAM budget %d, expected %d", memory, budget, expected)
+	}
+}
@@ -0,0 +1,106 @@
+package ObjectBased
+
+import (
+	"fmt"
+	"log"
+)
+
+/**
+	k-hit admission (model "kHit"): a missed object is only written to flash on its k-th miss within a window.
+	Misses are counted in two counting Bloom filters, one for the current window of kHitWindow requests and one
+	for the previous window. When the window is over, the current filter becomes the previous one and a new
+	empty filter is used, so memory stays bounded and old misses are forgotten after two windows.
+	The count of an object is the sum of its counts in both filters. Bloom filters only overestimate counts,
+	so some objects are admitted early, never late.
+	One-hit-wonders filtered are objects rejected on their first miss and not requested again within the
+	filters, counted from the filters as well: a miss with a count of exactly one is the return of an object
+	rejected on its first miss.
+ */
+
+var (
+	kHitK				int
+	kHitWindow			int64
+	kHitExpected		int
+	kHitFPRate			float64
+	kHitCurrent			*CountingBloomFilter
+	kHitPrevious		*CountingBloomFilter
+	kHitWindowEnd		int64
+
+	/* experiment part */
+	kHitAdmits			int64
+	kHitRejects			int64
+	kHitFirstRejects	int64				// rejected on the first miss
+	kHitReturns			int64				// missed again after being rejected on the first miss
+)
+
+/**
+	Set up k-hit admission. Should be called after StartUp.
+	k: admit on the k-th miss. window: length of one window in requests.
+	expected: number of distinct missed objects in one window, fpRate: false positive rate of the filters.
+ */
+func KHitSetUp(k int, window int64, expected int, fpRate float64) {
+	if k < 1 || window <= 0 {
+		log.Fatalf("Wrong k-hit admission: k %d, window %d.\n", k, window)
+	}
+	kHitK = k
+	kHitWindow = window
+	kHitExpected = expected
+	kHitFPRate = fpRate
+	kHitCurrent = newCountingBloomFilter(expected, fpRate)
+	kHitPrevious = newCountingBloomFilter(expected, fpRate)
+	kHitWindowEnd = numRequest + window
+
+	kHitAdmits = 0
+	kHitRejects = 0
+	kHitFirstRejects = 0
+	kHitReturns = 0
+}
+
+func rotateKHit() {
+	for numRequest >= kHitWindowEnd {
+		kHitPrevious = kHitCurrent
+		kHitCurrent = newCountingBloomFilter(kHitExpected, kHitFPRate)
+		kHitWindowEnd += kHitWindow
+	}
+}
+
+/**
+	Return true if this miss is at least the k-th one of the object in the last two windows.
+ */
+func admissionKHit(id string, size int64) bool {
+	rotateKHit()
+
+	misses := kHitCurrent.count(id) + kHitPrevious.count(id) + 1
+	if misses == 2 {
+		kHitReturns++
+	}
+	if misses >= kHitK {
+		kHitAdmits++
+		return true
+	}
+	kHitCurrent.increment(id)
+	kHitRejects++
+	if misses == 1 {
+		kHitFirstRejects++
+	}
+	return false
+}
+
+/**
+	Return k-hit results.
+	1. Admission ratio: admitted misses / all misses
+	2. One-hit-wonders filtered: objects rejected on their first miss and not requested again within two windows
+	3. Memory used by the filters (Bytes)
+ */
+func GetKHitResults() (float64, int64, int64) {
+	ratio := 0.0
+	if kHitAdmits + kHitRejects > 0 {
+		ratio = float64(kHitAdmits) / float64(kHitAdmits + kHitRejects)
+	}
+	memory := int64(len(kHitCurrent.counters) + len(kHitPrevious.counters))
+	oneHitWonders := kHitFirstRejects - kHitReturns
+	fmt.Printf("k-hit:: k: %d, admits: %d, rejects: %d, admission ratio: %f, first miss rejects: %d, "+
+		"one-hit-wonders filtered: %d, filter memory: %d bytes.\n",
+		kHitK, kHitAdmits, kHitRejects, ratio, kHitFirstRejects, oneHitWonders, memory)
+	return ratio, oneHitWonders, memory
+}
@@ -0,0 +1,47 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestKHitAdmitsOnKthMiss(t *testing.T) {
+	testStartUp(t)
+	KHitSetUp(3, 1000, 1000, 0.001)
+	for miss := 1; miss <= 3; miss++ {
+		if admit := admissionKHit("a", 100); admit != (miss == 3) {
+			t.Fatalf("miss %d: admit %t", miss, admit)
+		}
+	}
+}
+
+func TestKHitForgetsAfterTwoWindows(t *testing.T) {
+	testStartUp(t)
+	KHitSetUp(2, 1000, 1000, 0.001)
+	admissionKHit("a", 100)
+	numRequest += 2000
+	if admissionKHit("a", 100) {
+		t.Fatalf("miss from two windows ago still counted")
+	}
+}
+
+func TestKHitOneHitWonders(t *testing.T) {
+	testStartUp(t)
+	KHitSetUp(2, 100000, 100000, 0.001)
+	memory := int64(len(kHitCurrent.counters) + len(kHitPrevious.counters))
+	for id := 0; id < 10000; id++ {
+		numRequest++
+		admissionKHit(strconv.Itoa(id), 100)
+	}
+	for id := 0; id < 1000; id++ {
+		numRequest++
+		admissionKHit(strconv.Itoa(id), 100)
+	}
+	_, oneHitWonders, used := GetKHitResults()
+	if oneHitWonders < 8900 || oneHitWonders > 9000 {
+		t.Fatalf("one-hit-wonders: %d, expected about 9000", oneHitWonders)
+	}
+	if used != memory {
+		t.Fatalf("memory grew from %d to %d bytes", memory, used)
+	}
+}
@@ -0,0 +1,242 @@
+package ObjectBased
+
+import (
+	"container/list"
+	"hash/fnv"
+	"log"
+)
+
+/**
+	Small-object tier modeled after Kangaroo (Mcallister et al., SOSP 2021).
+	Objects smaller than kangarooThreshold do not go to the size-class boxes. They are appended to a tiny
+	log (KLog) made of boxes. When the log is full, its oldest box is evicted and the live objects in it are
+	grouped by set. Each set is one flash page, chosen by hashing the object id. If at least
+	kSetAdmitThreshold objects map to the same set, they are written into that set together (one page write),
+	otherwise they are dropped. Inside a set, objects are evicted by RRIP.
+	Only the log needs an index in DRAM. Sets are found by hashing.
+ */
+
+const kMaxRRPV = 7		// 3-bit re-reference prediction value
+
+type kSetEntry struct {
+	objectId	string
+	objectSize	int64
+	rrpv		uint8
+}
+
+var (
+	kangarooThreshold	int64				// objects smaller than this go to Kangaroo. 0 --> disabled
+	kLogCapacity		int64
+	kLogBoxSize			int64
+	kPageSize			int64
+	kSetAdmitThreshold	int
+	kLogOpen			*Box
+	kLogQueue			*list.List			// sealed log boxes, oldest in the front
+	kLogSize			int64
+	kLogIndex			map[string]int64	// object id --> log box id
+	kLogObjSize			map[string]int64	// object id --> size, for objects in the log
+	kSets				[][]*kSetEntry
+	kSetUsed			[]int64
+
+	/* experiment part */
+	kLogHits			int64
+	kSetHits			int64
+	kLogWrites			int64				// bytes written into the log
+	kSetWrites			int64				// number of page writes into sets
+	kDropped			int64				// objects dropped when leaving the log
+)
+
+/**
+	Set up the small-object tier. Should be called after StartUp.
+	threshold: objects smaller than threshold (Bytes) use this tier.
+	logSize: flash size of the log. logBoxSize: size of one log box.
+	setSize: flash size of the set-associative layer. pageSize: size of one set.
+	admit: minimum number of objects moved into one set together.
+ */
+func KangarooSetUp(threshold int64, logSize int64, logBoxSize int64, setSize int64, pageSize int64, admit int) {
+	if pageSize <= 0 || setSize < pageSize || logBoxSize <= 0 {
+		log.Fatalf("Wrong Kangaroo configuration: set size %d, page size %d, log box size %d.\n",
+			setSize, pageSize, logBoxSize)
+	}
+	kangarooThreshold = threshold
+	kLogCapacity = logSize
+	kLogBoxSize = logBoxSize
+	kPageSize = pageSize
+	kSetAdmitThreshold = admit
+	kLogOpen = newKLogBox()
+	kLogQueue = list.New()
+	kLogSize = 0
+	kLogIndex = make(map[string]int64)
+	kLogObjSize = make(map[string]int64)
+	numSets := setSize / pageSize
+	kSets = make([][]*kSetEntry, numSets)
+	kSetUsed = make([]int64, numSets)
+
+	kLogHits = 0
+	kSetHits = 0
+	kLogWrites = 0
+	kSetWrites = 0
+	kDropped = 0
+}
+
+func newKLogBox() *Box {
+	box := &Box{
+		boxId:			nextBoxId,
+		currSize:		0,
+		upperBound:		kangarooThreshold,
+		objOffsetMap:	make(map[string]int64),
+		maxSize:		kLogBoxSize,
+	}
+	nextBoxId++
+	return box
+}
+
+func kSetIndex(id string) int {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return int(h.Sum64() % uint64(len(kSets)))
+}
+
+/**
+	Request one small object. Look up the log first, then its set. Return true if it is a hit.
+	A copy with a different size is stale: it is dropped and the request is a miss.
+ */
+func kangarooRequest(id string, objectSize int64, model string) bool {
+	if _, ok := kLogIndex[id]; ok {
+		if kLogObjSize[id] == objectSize {
+			kLogHits++
+			return true
+		}
+		delete(kLogIndex, id)
+		delete(kLogObjSize, id)
+	}
+
+	set := kSetIndex(id)
+	for index, entry := range kSets[set] {
+		if entry.objectId != id {
+			continue
+		}
+		if entry.objectSize == objectSize {
+			kSetHits++
+			entry.rrpv = 0
+			return true
+		}
+		kSetRemove(set, index)
+		break
+	}
+
+	totalMiss += objectSize
+	if !admission(model, id, objectSize) {
+		return false
+	}
+	admitMiss += objectSize
+	kLogInsert(id, objectSize)
+	return false
+}
+
+/**
+	Append the object into the open log box. Seal it when full, and evict log boxes when the log is full.
+ */
+func kLogInsert(id string, objectSize int64) {
+	if kLogOpen.currSize + objectSize > kLogOpen.maxSize {
+		for kLogSize + kLogBoxSize > kLogCapacity && kLogQueue.Len() > 0 {
+			kLogEvict()
+		}
+		kLogQueue.PushBack(kLogOpen)
+		kLogSize += kLogBoxSize
+		kLogWrites += kLogBoxSize
+		writeBudget.chargeSeal(kLogBoxSize, kLogBoxSize - kLogOpen.currSize)
+		kLogOpen = newKLogBox()
+	}
+	kLogOpen.objOffsetMap[id] = kLogOpen.currSize
+	kLogOpen.currSize += objectSize
+	kLogIndex[id] = kLogOpen.boxId
+	kLogObjSize[id] = objectSize
+}
+
+/**
+	Evict the oldest log box and move its live objects into their sets.
+ */
+func kLogEvict() {
+	box := kLogQueue.Remove(kLogQueue.Front()).(*Box)
+	kLogSize -= kLogBoxSize
+
+	bySet := make(map[int][]string)
+	for id := range box.objOffsetMap {
+		if boxId, ok := kLogIndex[id]; !ok || boxId != box.boxId {
+			continue
+		}
+		set := kSetIndex(id)
+		bySet[set] = append(bySet[set], id)
+	}
+
+	for set, ids := range bySet {
+		if len(ids) >= kSetAdmitThreshold {
+			for _, id := range ids {
+				kSetInsert(set, id, kLogObjSize[id])
+			}
+			kSetWrites++
+			writeBudget.chargeGC(kPageSize)
+		} else {
+			kDropped += int64(len(ids))
+		}
+		for _, id := range ids {
+			delete(kLogIndex, id)
+			delete(kLogObjSize, id)
+		}
+	}
+}
+
+/**
+	Insert one object into a set, evicting by RRIP until it fits.
+ */
+func kSetInsert(set int, id string, objectSize int64) {
+	if objectSize > kPageSize {
+		kDropped++
+		return
+	}
+	for index, entry := range kSets[set] {
+		if entry.objectId == id {
+			// stale copy in the set
+			kSetRemove(set, index)
+			break
+		}
+	}
+	for kSetUsed[set] + objectSize > kPageSize {
+		kSetEvict(set)
+	}
+	kSets[set] = append(kSets[set], &kSetEntry{id, objectSize, kMaxRRPV - 1})
+	kSetUsed[set] += objectSize
+}
+
+func kSetRemove(set int, index int) {
+	kSetUsed[set] -= kSets[set][index].objectSize
+	kSets[set] = append(kSets[set][:index], kSets[set][index + 1:]...)
+}
+
+/**
+	RRIP: evict the first object predicted to be re-referenced furthest in the future.
+	If there is none, age every object in the set and look again.
+ */
+func kSetEvict(set int) {
+	for {
+		for index, entry := range kSets[set] {
+			if entry.rrpv >= kMaxRRPV {
+				kSetRemove(set, index)
+				return
+			}
+		}
+		for _, entry := range kSets[set] {
+			entry.rrpv++
+		}
+	}
+}
+
+/**
+	Return Kangaroo specific results: log hits, set hits, bytes written into the log and page writes into sets.
+ */
+func GetKangarooResults() (int64, int64, int64, int64) {
+	DFmtPrintf("Kangaroo:: log hits: %d, set hits: %d, log writes: %d bytes, set writes: %d pages, dropped: %d.\n",
+		kLogHits, kSetHits, kLogWrites, kSetWrites, kDropped)
+	return kLogHits, kSetHits, kLogWrites, kSetWrites
+}
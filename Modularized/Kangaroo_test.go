@@ -0,0 +1,57 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+func kangarooStartUp(t *testing.T) {
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	KangarooSetUp(2000, 128 << 10, 64 << 10, 1 << 20, 4096, 1)
+}
+
+func TestKangarooLogSizeChange(t *testing.T) {
+	kangarooStartUp(t)
+	defer func() { kangarooThreshold = 0 }()
+	for index, expected := range []struct {
+		size	int64
+		hit		bool
+	}{{100, false}, {100, true}, {200, false}, {200, true}} {
+		if hit := kangarooRequest("a", expected.size, "lameDuck"); hit != expected.hit {
+			t.Fatalf("request %d of %d bytes: hit %t", index, expected.size, hit)
+		}
+	}
+}
+
+func TestKangarooSetSizeChange(t *testing.T) {
+	kangarooStartUp(t)
+	defer func() { kangarooThreshold = 0 }()
+	kangarooRequest("a", 100, "lameDuck")
+	for id := 0; id < 2000; id++ {
+		kangarooRequest(strconv.Itoa(id), 100, "lameDuck")
+	}
+	if _, ok := kLogIndex["a"]; ok {
+		t.Fatalf("a is still in the log")
+	}
+	if !kangarooRequest("a", 100, "lameDuck") || kSetHits != 1 {
+		t.Fatalf("a is not found in its set")
+	}
+	if kangarooRequest("a", 300, "lameDuck") {
+		t.Fatalf("stale copy in the set served")
+	}
+	set := kSetIndex("a")
+	var used int64
+	for _, entry := range kSets[set] {
+		if entry.objectId == "a" {
+			t.Fatalf("stale copy kept in the set")
+		}
+		used += entry.objectSize
+	}
+	if used != kSetUsed[set] {
+		t.Fatalf("set holds %d bytes, accounted %d", used, kSetUsed[set])
+	}
+	if !kangarooRequest("a", 300, "lameDuck") {
+		t.Fatalf("new copy not admitted into the log")
+	}
+}
@@ -1,12 +1,12 @@
 package ObjectBased
 
 import (
+	"awesomeProject/Chunk"
 	"container/list"
 	"strconv"
 	"fmt"
 )
 
-const maxBoxSize = 104857600		// 100 MB
 const Epoch = 1000000				// 1 million
 
 type Object struct {
@@ -19,6 +19,13 @@ type Box struct {
 	currSize	int64					// record the current size of the box
 	upperBound	int64					// the upper bound of object size this box can hold
 	objOffsetMap map[string]int64		// map from object id to the offset where this object is stored. --> not required in simulation
+	freq		int						// hits since last checked by FIFO-based eviction (SIEVE, S3FIFO, CLOCK)
+	filter		*BloomFilter			// built when the box is sealed, only in bloom lookup mode
+	openedAt	int64					// request number when the first object was added
+	openedTime	int64					// trace time when the first object was added
+	maxSize		int64					// box (erase block) size
+	sealedAt	int64					// request number when the box was sealed
+	generation	int64					// size classes in use when the box was sealed, see liveGranularity
 }
 
 type QueuePos struct {
@@ -28,17 +35,22 @@ type QueuePos struct {
 
 type AccessCount struct {
 	objectId 		string
+	objectSize		int64
 }
 
 type GhostCache struct {
-	maxSize			int64
+	maxSize			int64			// in objects, or in bytes if inBytes
 	currSize		int64
+	inBytes			bool
 	accessCount		map[string]int	// map. object id --> access count
 	queue 			*list.List		// when ghost cache is full, evict some objects.
 	objQueueMap		map[string]*list.Element
 }
 
 var (
+	maxBoxSize		int64 = 104857600	// default box size, 100 MB
+	classBoxSizes	[]int64				// size class index --> box size, as given to SetClassBoxSizes
+	boundBoxSizes	map[int64]int64		// upper bound --> box size, overrides maxBoxSize
 	hotQueue		*list.List		// holds box
 	coldQueue		*list.List
 	hotSize 		int64
@@ -50,6 +62,7 @@ var (
 	nextBoxId		int64				// record next box Id
 	maxObjSize		int64
 	cachedObj		map[string]int64 	// object id --> box id
+	cachedKeyBytes	int64				// total length of the keys in cachedObj
 
 	/* experiment part */
 	numSeal			int64				// number of sealed boxes
@@ -61,15 +74,23 @@ var (
 	/* over time */
 	//MissBytes				int64
 	fragRatio 				float64
+	fragBytes				int64			// unused bytes in sealed boxes
+	sealedBytes				int64			// total size of sealed boxes
 	SealedBoxRatioTime		[]float64		// how sealed box ratio varies with time
 	SealedBoxNumber			[]int64
 	HitRatioTime			[]float64		// how hit ratio varies with time
 	HitBytesRatioTime		[]float64
 	MissBytesRatioTime		[]float64
 	NumberOfRequests		[]int64
+	IndexMemoryTime			[]int64			// estimated DRAM used by the index
 
 
 	/* dynamic granularity */
+	dynamicGranularity		bool
+	granularityClasses		int					// number of size classes asked for
+	GranularityHistory		[]GranularityChange	// every change of the size classes, append only
+	liveGranularity			[]liveClasses		// size classes with live sealed boxes, and the current ones
+	granularityGeneration	int64
 	count					map[float64]int		// map from power --> number of objects
 )
 
@@ -83,37 +104,58 @@ var (
  */
 func StartUp(cacheSize int64, number int, objSize int64, quota int64) {
 //func StartUp(cacheSize int64, number int, log bool, objSize int64, statPath string) {
+	maxObjSize = objSize
+	StartUpWithGranularity(cacheSize, EqualLogGranularity(uint(number)), objSize, quota)
+	dynamicGranularity = true
+}
+
+/**
+	Same as StartUp, with the given upper bounds of the size classes, e.g. from OptimalGranularity.
+	The size classes stay fixed for the whole run.
+ */
+func StartUpWithGranularity(cacheSize int64, upperBounds []int64, objSize int64, quota int64) {
 	fmt.Println("Modularized test.")
 	maxCacheSize = cacheSize / 2
 	maxObjSize = objSize
+	dynamicGranularity = false
 	DDPrintf("StartUp:: Cache size is: %d, cold/hot queue size: %d.\n", cacheSize, maxCacheSize)
 	hotQueue = list.New()
 	coldQueue = list.New()
 	hotSize = 0
 	coldSize = 0
 	nextBoxId = 1
-	openBoxes = make(map[int64]*Box, number)
-	granularity = EqualLogGranularity(uint(number))
+	openBoxes = make(map[int64]*Box, len(upperBounds))
+	granularity = uniqueBounds(upperBounds)
+	granularityClasses = len(upperBounds)
+	granularityGeneration = 0
+	GranularityHistory = []GranularityChange{{Request: 0, Bounds: granularity}}
+	liveGranularity = []liveClasses{{bounds: granularity}}
 	fmt.Println(granularity)
+	boundBoxSizes = make(map[int64]int64, len(granularity))
+	for index, bound := range granularity {
+		if index < len(classBoxSizes) && classBoxSizes[index] > 0 {
+			boundBoxSizes[bound] = classBoxSizes[index]
+		}
+	}
 	boxQueueMap = make(map[int64]*QueuePos)
 	for _, upperBound := range granularity {
-		newBox := &Box {
-			boxId:			nextBoxId,
-			currSize: 		0,
-			upperBound:		upperBound,
-			objOffsetMap:	make(map[string]int64),
-		}
-		nextBoxId++
-		openBoxes[upperBound] = newBox
+		newOpenBox(upperBound)
 	}
 
+	boxEvictionSetUp()
+	openBoxSetUp()
+	lookupSetUp()
+	classStatSetUp()
+	seedSetUp()
+	admissionReset()
+
 	// experiment part
 	basicSetUp()
 
 	// new graph
 	timeSetUp()
 
-	//AngryBearSetUp(quota)
+	//AngryBearSetUp()
 
 	ProbSetUp(quota)
 }
@@ -126,6 +168,10 @@ func basicSetUp() {
 	hitBytes = 0
 	reqBytes = 0
 	cachedObj = make(map[string]int64)
+	cachedKeyBytes = 0
+	oversizedRequests = 0
+	oversizedBytes = 0
+	rangeStats = Chunk.Stats{}
 	count = make(map[float64]int)
 }
 
@@ -136,24 +182,61 @@ func timeSetUp() {
 	HitBytesRatioTime = make([]float64, 0)
 	MissBytesRatioTime = make([]float64, 0)
 	fragRatio = 0
+	fragBytes = 0
+	sealedBytes = 0
 	NumberOfRequests = make([]int64, 0)
+	IndexMemoryTime = make([]int64, 0)
 }
 
 /**
 	Deal with new command.
  */
 func Request(id string, size string, model string) {
+	objectSize := newRequest(id, size)
+	serveRequest(id, size, objectSize, 0, objectSize - 1, model)
+}
+
+/**
+	Deal with a range request: only bytes [start, end] (inclusive) of the object are requested.
+ */
+func RangeRequest(id string, size string, start int64, end int64, model string) {
+	objectSize := newRequest(id, size)
+	serveRequest(id, size, objectSize, start, end, model)
+}
+
+/**
+	Serve bytes [start, end] of the object from DRAM or flash, and charge the request to its size class.
+ */
+func serveRequest(id string, size string, objectSize int64, start int64, end int64, model string) {
+	bytes := Chunk.RangeBytes(objectSize, start, end)
+	reqBytes += bytes
+	class := requestClass(objectSize)
+	lastHits, lastHitBytes := hits, hitBytes
+
+	if dramPolicy != "" {
+		dramRequest(id, size, objectSize, start, end, model)
+	} else {
+		flashRequest(id, objectSize, start, end, model)
+	}
+	classRecord(class, bytes, hits - lastHits, hitBytes - lastHitBytes)
+}
+
+/**
+	Update counters and periodic statistics for a new request. Return the object size.
+ */
+func newRequest(id string, size string) int64 {
 	//fmt.Printf("New request: %s with size %s.\n", id, size)
 	DPrintf("Request:: request object %s with size %s.\n", id, size)
 	numRequest++
+	checkOpenBoxAge()
 	collectStat(size)		// dynamic granularity
 
 	//updateFixedProb(model)
 	//updateAvgProb()
-	updateImprovedProb()
+	writeBudget.tick()
 
 
-	if numRequest < 250 * Epoch {
+	if numRequest < warmUpRequests {
 		getResultsWithTime()
 	} else {
 		//updateTire()
@@ -166,16 +249,65 @@ func Request(id string, size string, model string) {
 	if err != nil {
 		DPrintf("Input size %s cannot be converted to int64 type with error %s.\n", size, err)
 	}
-	reqBytes += objectSize
+	return objectSize
+}
 
+/**
+	Look up the flash cache for bytes [start, end] of the object and update hit statistics.
+	Return true if all requested bytes are cached.
+	Objects which do not fit any size class are split into chunks if chunking is enabled.
+	Otherwise, they bypass the cache and count as misses.
+ */
+func flashRequest(id string, objectSize int64, start int64, end int64, model string) bool {
+	if chunked(objectSize) {
+		return chunkedRequest(id, objectSize, start, end, model)
+	}
+	if oversized(objectSize) {
+		DPrintf("Object size %d exceeds the maximum box size.\n", objectSize)
+		oversizedRequests++
+		oversizedBytes += objectSize
+		rangeStats.Record(false, objectSize, start, end)
+		return false
+	}
+	hit := flashLookup(id, objectSize, model)
+	rangeStats.Record(hit, objectSize, start, end)
+	if hit {
+		hits++
+		hitBytes += Chunk.RangeBytes(objectSize, start, end)
+	}
+	return hit
+}
+
+/**
+	Whether the object fits neither the Kangaroo tier nor any size class.
+ */
+func oversized(objectSize int64) bool {
+	if objectSize < kangarooThreshold {
+		return false
+	}
 	// get the upper bound --> might be greater than maximum object size --> not allowed
 	bound := getBound(objectSize)
-	DPrintf("%s should be put into open box with upper bound %d.\n", id, bound)
-	if bound == -1 {
-		DPrintf("Object size %s exceeds the maximum box size.\n", size)
-		return
+	return bound == -1 || objectSize > openBoxes[bound].maxSize
+}
+
+/**
+	Look up the flash cache. On a miss, the object is admitted into an open box. Return true if it is a hit.
+ */
+func flashLookup(id string, objectSize int64, model string) bool {
+	switch model {
+	case "flashield":
+		flashieldObserve(id, objectSize)
+	case "adaptSize":
+		adaptSize.Observe(id, objectSize)
+	}
+	// small objects are served by the Kangaroo tier
+	if objectSize < kangarooThreshold {
+		return kangarooRequest(id, objectSize, model)
 	}
 
+	bound := getBound(objectSize)
+	DPrintf("%s should be put into open box with upper bound %d.\n", id, bound)
+
 	// First check whether the object is in open box. If it is, consider as one hit.
 	openBox, _ := openBoxes[bound]
 	_, ok := openBox.objOffsetMap[id]
@@ -183,11 +315,12 @@ func Request(id string, size string, model string) {
 
 	if !ok {
 		// Not in open boxes
-		boxId, isSealed := cachedObj[id]
+		boxId, isSealed := lookupSealed(id, objectSize)
 		if isSealed {
 			// object is found in cache
 			cachedObject(objectSize, id, boxId)
 			//updateGhostQueue(id, true)
+			return true
 		} else {
 			// Object is not cached. Add it to corresponding open box.
 
@@ -207,9 +340,12 @@ func Request(id string, size string, model string) {
 				return
 			}
 			*/
+			if boxEviction == "PARTITION" {
+				partitionMiss(bound, id)
+			}
 			totalMiss += objectSize
-			if !warmUpImprovedProb(model, objectSize) {
-				return
+			if !admission(model, id, objectSize) {
+				return false
 			}
 
 			//if !warmUpTIRE(id, objectSize) {
@@ -226,14 +362,87 @@ func Request(id string, size string, model string) {
 			admitMiss += objectSize
 			//updateGhostQueue(id, false)
 			addToOpenBox(openBox, objectSize, bound, id)
+			return false
 		}
 	} else {
 		// in open boxes
-		hits++
-		hitBytes += objectSize
+		bufferHits++
+		bufferHitBytes += objectSize
 		//updateGhostQueue(id, true)
+		return true
 	}
+}
+
+/**
+	Check the admission model given to Request: one of the policies below, or an admission curve of the
+	improved probability controller. Should be called before the first request, after StartUp and the SetUp
+	function of the policy. Return an error if the policy is not set up.
+	For an admission curve, the curve is resolved here once and used by every following miss.
+ */
+func AdmissionSetUp(model string) error {
+	var ready bool
+	switch model {
+	case "TIRE":
+		ready = ghostCache != nil && len(intervals) > 1
+	case "kHit":
+		ready = kHitCurrent != nil
+	case "flashield":
+		ready = flashieldGhost != nil
+	case "PID":
+		ready = pidMode != ""
+	case "adaptSize":
+		ready = adaptSize != nil
+	default:
+		curve, err := ProbCurve(model)
+		if err != nil {
+			return fmt.Errorf("wrong admission model %s: %v", model, err)
+		}
+		admissionCurve = curve
+		return nil
+	}
+	if !ready {
+		return fmt.Errorf("admission model %s is not set up, its SetUp function should be called after StartUp", model)
+	}
+	return nil
+}
+
+/**
+	Forget the admission policies of the last run, every policy is set up again after StartUp.
+ */
+func admissionReset() {
+	ghostCache = nil
+	intervals = nil
+	kHitCurrent = nil
+	kHitPrevious = nil
+	flashieldGhost = nil
+	pidMode = ""
+	adaptSize = nil
+	admissionCurve = curveLine
+}
 
+/**
+	Admission control for a missed object. Shared by the size-class boxes and the Kangaroo tier.
+ */
+func admission(model string, id string, size int64) bool {
+	var admit bool
+	switch model {
+	case "TIRE":
+		admit = warmUpTIRE(id, size)
+	case "kHit":
+		admit = admissionKHit(id, size)
+	case "flashield":
+		admit = admissionFlashield(id)
+	case "PID":
+		admit = admissionPID(size)
+	case "adaptSize":
+		admit = adaptSize.Admit(id, size)
+	default:
+		admit = warmUpImprovedProb(size)
+	}
+	if admit {
+		writeBudget.chargeAdmission(size)
+	}
+	return admit
 }
 
 /**
@@ -242,19 +451,86 @@ func Request(id string, size string, model string) {
 	open box to hold this object. Otherwise, add it into the open box.
  */
 func addToOpenBox(box *Box, objectSize int64, bound int64, id string) {
-	if box.currSize + objectSize > maxBoxSize {
+	if box.currSize + objectSize > box.maxSize {
 		// open box is full --> seal.
-		updateColdQueue(box)
-		addObjects(box)
-		fragRatio += float64(maxBoxSize - box.currSize) / float64(maxBoxSize)
-		numSeal++
-
-		box = &Box{nextBoxId, 0,  bound, make(map[string]int64)}
-		nextBoxId++
-		openBoxes[bound] = box
+		sealBox(box)
+		box = newOpenBox(bound)
+	}
+	if box.currSize == 0 {
+		box.openedAt = numRequest
+		box.openedTime = currTime
 	}
 	box.objOffsetMap[id] = box.currSize
 	box.currSize += objectSize
+	openBytes += objectSize
+	checkOpenBuffer()
+}
+
+/**
+	Create an empty open box for the upper bound.
+ */
+func newOpenBox(bound int64) *Box {
+	box := &Box{
+		boxId:			nextBoxId,
+		currSize:		0,
+		upperBound:		bound,
+		objOffsetMap:	make(map[string]int64),
+		maxSize:		boxSizeFor(bound),
+	}
+	nextBoxId++
+	openBoxes[bound] = box
+	return box
+}
+
+/**
+	Set the default box size (Bytes). Should be called before StartUp.
+ */
+func SetBoxSize(size int64) {
+	maxBoxSize = size
+}
+
+/**
+	Set the box size of every size class, in the same order as granularity. Classes without a size
+	use the default box size. Should be called before StartUp. When the size classes change, a new class takes
+	the box size of the old class its upper bound belonged to.
+ */
+func SetClassBoxSizes(sizes []int64) {
+	classBoxSizes = sizes
+}
+
+/**
+	Box size of the size class with the given upper bound.
+ */
+func boxSizeFor(bound int64) int64 {
+	if size, ok := boundBoxSizes[bound]; ok {
+		return size
+	}
+	return maxBoxSize
+}
+
+func classBoxSize(index int) int64 {
+	if index < len(classBoxSizes) && classBoxSizes[index] > 0 {
+		return classBoxSizes[index]
+	}
+	return maxBoxSize
+}
+
+/**
+	Seal one open box: write it into flash according to the box eviction policy and index its objects.
+ */
+func sealBox(box *Box) {
+	openBytes -= box.currSize
+	writeBudget.chargeSeal(box.maxSize, box.maxSize - box.currSize)
+	box.sealedAt = numRequest
+	granularitySeal(box)
+	classSeal(box)
+	sealBoxFilter(box)
+	insertSealedBox(box)
+	addObjects(box)
+	fragRatio += float64(box.maxSize - box.currSize) / float64(box.maxSize)
+	fragBytes += box.maxSize - box.currSize
+	sealedBytes += box.maxSize
+	numSeal++
 }
 
 /**
@@ -267,6 +543,9 @@ func addObjects(box *Box) {
 
 	for key, _ := range objOffSet {
 		DPrintf("key is %s.\n", key)
+		if _, ok := cachedObj[key]; !ok {
+			cachedKeyBytes += int64(len(key))
+		}
 		cachedObj[key] = boxid
 	}
 	DDPrintf("addObjects:: current cached objects: %d.\n", len(cachedObj))
@@ -274,13 +553,17 @@ func addObjects(box *Box) {
 
 
 /**
-	Object is cached. If in hot queue, just update the hot queue. Otherwise, update both hot and cold queue.
+	Object is cached. Update the box queues according to the box eviction policy.
  */
 func cachedObject(objectSize int64, id string, boxId int64) {
 	DPrintf("cachedObject:: object %s is cached in box %d.\n", id, boxId)
-	hits++
-	hitBytes += objectSize
+	hitSealedBox(boxId)
+}
 
+/**
+	Sealed box is hit under S2LRU. If in hot queue, just update the hot queue. Otherwise, update both hot and cold queue.
+ */
+func hitS2LRU(boxId int64) {
 	sealedBoxPos := boxQueueMap[boxId]
 	element := sealedBoxPos.element.Value.(*Box)
 
@@ -307,11 +590,11 @@ func pushToQueue(box *Box, hot bool) {
 	if hot {
 		hotQueue.PushBack(box)
 		newPos = &QueuePos{hotQueue.Back(), true}
-		hotSize += maxBoxSize
+		hotSize += box.maxSize
 	} else {
 		coldQueue.PushBack(box)
 		newPos = &QueuePos{coldQueue.Back(), false}
-		coldSize += maxBoxSize
+		coldSize += box.maxSize
 	}
 	boxQueueMap[box.boxId] = newPos
 }
@@ -322,12 +605,13 @@ func pushToQueue(box *Box, hot bool) {
 func removeFromQueue(element *list.Element, hot bool) {
 	//DPrintf("removeFromQueue:: before removing box %d from queue.\n", element.Value.(*Box).boxId)
 
+	size := element.Value.(*Box).maxSize
 	if hot {
 		hotQueue.Remove(element)
-		hotSize -= maxBoxSize
+		hotSize -= size
 	} else {
 		coldQueue.Remove(element)
-		coldSize -= maxBoxSize
+		coldSize -= size
 	}
 }
 
@@ -338,9 +622,9 @@ func removeFromQueue(element *list.Element, hot bool) {
 func updateColdQueue(box *Box) {
 	DPrintf("updateColdQueue:: before updating: " )
 	PrintQueue(hotQueue, false)
-	if coldSize + maxBoxSize > maxCacheSize {
+	for coldSize + box.maxSize > maxCacheSize && coldQueue.Len() > 0 {
 		// cold queue is full
-		removeObjects(coldQueue.Front().Value.(*Box))
+		evictBox(coldQueue.Front().Value.(*Box))
 		removeFromQueue(coldQueue.Front(), false)
 	}
 	pushToQueue(box, false)
@@ -356,10 +640,11 @@ func updateColdQueue(box *Box) {
 func updateHotQueue(box *Box) {
 	DPrintf("updateHotQueue:: before updating: " )
 	PrintQueue(hotQueue, true)
-	if hotSize + maxBoxSize > maxCacheSize {
+	for hotSize + box.maxSize > maxCacheSize && hotQueue.Len() > 0 {
 		// hot queue is full.
-		updateColdQueue(hotQueue.Front().Value.(*Box))
-		removeFromQueue(hotQueue.Front(), true)
+		front := hotQueue.Front()
+		updateColdQueue(front.Value.(*Box))
+		removeFromQueue(front, true)
 	}
 	pushToQueue(box, true)
 	DPrintf("updateHotQueue:: after updating: " )
@@ -377,6 +662,9 @@ func removeObjects(box *Box) {
 
 	for key, _ := range objOffset {
 		DPrintf("key is %s.\n", key)
+		if _, ok := cachedObj[key]; ok {
+			cachedKeyBytes -= int64(len(key))
+		}
 		delete(cachedObj, key)
 	}
 	DDPrintf("removeObjects:: current cached objects: %d.\n", len(cachedObj))
@@ -399,7 +687,7 @@ func getBound(size int64) int64 {
 
 /**
 	Return experiment results
-	1. WCR: waste cache ratio, percentage of wasted space --> bytes of fragmentation / total used cache size
+	1. WCR: waste cache ratio, percentage of wasted space --> bytes of fragmentation / total sealed box bytes
 	2. SBRR: sealed box request ratio, #sealed boxes / #requests
 	3. OHR: object hit ratio, #read hit / #requests
 	4. BHR: bytes hit ratio, #hit bytes / #requests
@@ -409,7 +697,12 @@ func GetResults() (float64, float64, float64, float64) {
 		numSeal, numRequest, hits, hitBytes, reqBytes)
 	fmt.Printf("fragRation: %f, numSeal: %d, numRequest: %d, hits: %d, hitBytes: %d, reqBytes: %d.\n",
 		fragRatio, numSeal, numRequest, hits, hitBytes, reqBytes)
-	WCR := fragRatio / float64(numSeal)
+	fmt.Printf("fragBytes: %d, sealedBytes: %d, seals in default box size: %f, bytes written: %d, seed: %d.\n",
+		fragBytes, sealedBytes, float64(sealedBytes) / float64(maxBoxSize), bytesWritten(), run.Seed)
+	printChunkResults()
+	printBeladyResults()
+	printClassResults()
+	WCR := float64(fragBytes) / float64(sealedBytes)
 	SBRR := float64(numSeal) / float64(numRequest)
 	OHR := float64(hits) / float64(numRequest)
 	BHR := float64(hitBytes) / float64(reqBytes)
@@ -432,6 +725,7 @@ func getResultsWithTime() {
 		SealedBoxNumber = append(SealedBoxNumber, numSeal)
 		HitRatioTime = append(HitRatioTime, float64(hits) / float64(numRequest))
 		HitBytesRatioTime = append(HitBytesRatioTime, float64(hitBytes) / float64(reqBytes))
+		IndexMemoryTime = append(IndexMemoryTime, indexMemory())
 		//MissBytesRatioTime = append(MissBytesRatioTime, float64(MissBytes) / float64(reqBytes))
 	}
 }
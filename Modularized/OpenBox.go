@@ -0,0 +1,137 @@
+package ObjectBased
+
+import (
+	"fmt"
+)
+
+/**
+	Open boxes normally seal only when the next object does not fit. Objects in open boxes are in RAM and
+	count as hits, so a rarely used size class can hold objects in RAM for the whole trace. Three ways to
+	bound this:
+	1. Seal on age: an open box is sealed once its first object is older than sealAge,
+	   counted in requests or in trace time (see RequestAt).
+	2. Seal on shutdown: FlushOpenBoxes seals every non-empty open box, e.g. before GetResults.
+	3. Bounded buffer: open boxes together may hold at most openBufferCapacity bytes. When the buffer is full,
+	   the oldest open box is sealed. Hits in open boxes are reported separately as buffer hits.
+ */
+
+var (
+	currTime			int64		// trace time of the current request
+	openBytes			int64		// bytes held by all open boxes
+	sealAge				int64		// 0 --> no seal on age
+	sealAgeByTime		bool		// age in trace time instead of requests
+	openBufferCapacity	int64		// 0 --> unbounded
+
+	/* experiment part */
+	ageSeals			int64
+	bufferSeals			int64
+	flushSeals			int64
+	bufferHits			int64
+	bufferHitBytes		int64
+)
+
+/**
+	Seal open boxes older than maxAge. If byTime is true, maxAge is in trace time, otherwise in requests.
+ */
+func SealTimeoutSetUp(maxAge int64, byTime bool) {
+	sealAge = maxAge
+	sealAgeByTime = byTime
+}
+
+/**
+	Treat open boxes as a DRAM buffer of the given capacity (Bytes). 0 --> unbounded.
+ */
+func OpenBufferSetUp(capacity int64) {
+	openBufferCapacity = capacity
+}
+
+func openBoxSetUp() {
+	currTime = 0
+	openBytes = 0
+	ageSeals = 0
+	bufferSeals = 0
+	flushSeals = 0
+	bufferHits = 0
+	bufferHitBytes = 0
+}
+
+/**
+	Same as Request, with the trace time of the request. Needed for seal on age in trace time.
+ */
+func RequestAt(timestamp int64, id string, size string, model string) {
+	currTime = timestamp
+	Request(id, size, model)
+}
+
+/**
+	Seal a non-empty open box before it is full and replace it with a new open box.
+ */
+func forceSeal(box *Box) bool {
+	if len(box.objOffsetMap) == 0 {
+		return false
+	}
+	sealBox(box)
+	newOpenBox(box.upperBound)
+	return true
+}
+
+func checkOpenBoxAge() {
+	if sealAge <= 0 {
+		return
+	}
+	for _, bound := range granularity {
+		box := openBoxes[bound]
+		if len(box.objOffsetMap) == 0 {
+			continue
+		}
+		age := numRequest - box.openedAt
+		if sealAgeByTime {
+			age = currTime - box.openedTime
+		}
+		if age >= sealAge && forceSeal(box) {
+			ageSeals++
+		}
+	}
+}
+
+/**
+	When open boxes hold more than the buffer capacity, seal the oldest ones.
+ */
+func checkOpenBuffer() {
+	if openBufferCapacity <= 0 {
+		return
+	}
+	for openBytes > openBufferCapacity {
+		var oldest *Box
+		for _, bound := range granularity {
+			box := openBoxes[bound]
+			if len(box.objOffsetMap) > 0 && (oldest == nil || box.openedAt < oldest.openedAt) {
+				oldest = box
+			}
+		}
+		if oldest == nil || !forceSeal(oldest) {
+			return
+		}
+		bufferSeals++
+	}
+}
+
+/**
+	Seal every non-empty open box. Should be called at the end of the trace.
+ */
+func FlushOpenBoxes() {
+	for _, bound := range granularity {
+		if forceSeal(openBoxes[bound]) {
+			flushSeals++
+		}
+	}
+}
+
+/**
+	Return open box results: seals on age, seals because the buffer was full, seals at flush and buffer hits.
+ */
+func GetOpenBoxResults() (int64, int64, int64, int64) {
+	fmt.Printf("Open boxes:: held bytes: %d, age seals: %d, buffer seals: %d, flush seals: %d, buffer hits: %d, buffer hit bytes: %d.\n",
+		openBytes, ageSeals, bufferSeals, flushSeals, bufferHits, bufferHitBytes)
+	return ageSeals, bufferSeals, flushSeals, bufferHits
+}
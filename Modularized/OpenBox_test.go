@@ -0,0 +1,48 @@
+package ObjectBased
+
+import "testing"
+
+func TestSealOnAge(t *testing.T) {
+	defer SealTimeoutSetUp(0, false)
+	for _, byTime := range []bool{false, true} {
+		SealTimeoutSetUp(3, byTime)
+		testStartUp(t)
+		WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+		// in requests the box of a is sealed at the fourth request, in trace time at time 3
+		for index, id := range []string{"a", "b", "c", "d"} {
+			RequestAt(int64(index), id, "100", "lameDuck")
+		}
+		if ageSeals != 1 || numSeal != 1 || openBytes != 100 {
+			t.Fatalf("by time %t: %d age seals, %d seals, %d bytes open", byTime, ageSeals, numSeal, openBytes)
+		}
+		if Request("a", "100", "lameDuck"); hits != 1 || bufferHits != 0 {
+			t.Fatalf("by time %t: a is not served from the sealed box", byTime)
+		}
+	}
+}
+
+func TestOpenBufferBounded(t *testing.T) {
+	defer OpenBufferSetUp(0)
+	OpenBufferSetUp(1000)
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	Request("a", "20", "lameDuck")
+	// b is in another size class and the buffer is full, the box of a is the oldest
+	Request("b", "990", "lameDuck")
+	if bufferSeals != 1 || numSeal != 1 || openBytes != 990 {
+		t.Fatalf("%d buffer seals, %d seals, %d bytes open", bufferSeals, numSeal, openBytes)
+	}
+	Request("b", "990", "lameDuck")
+	Request("a", "20", "lameDuck")
+	if hits != 2 || bufferHits != 1 || bufferHitBytes != 990 {
+		t.Fatalf("%d hits, %d buffer hits of %d bytes", hits, bufferHits, bufferHitBytes)
+	}
+
+	FlushOpenBoxes()
+	if ageSeals, bufferSeals, flushSeals, _ := GetOpenBoxResults(); ageSeals != 0 || bufferSeals != 1 || flushSeals != 1 || openBytes != 0 {
+		t.Fatalf("%d age seals, %d buffer seals and %d flush seals, %d bytes open", ageSeals, bufferSeals, flushSeals, openBytes)
+	}
+	if Request("b", "990", "lameDuck"); hits != 3 || bufferHits != 1 {
+		t.Fatalf("b is not served from the flushed box")
+	}
+}
@@ -0,0 +1,257 @@
+package ObjectBased
+
+import (
+	"awesomeProject/Chunk"
+	"bufio"
+	"log"
+	"math"
+	"os"
+	"sort"
+)
+
+/**
+	Offline search for the upper bounds of the size classes, given the size histogram of a trace.
+	Sizes are grouped into buckets of 1 / granBucketsPerDecade in log10, and the bounds are bucket edges.
+	The highest bound is always the maximum object size. The cost of one class with upper bound hi and box size B:
+	1. Fragmentation: a box is sealed when the next object does not fit, which leaves hi / 2 bytes unused on
+	   average. The class seals bytes / B boxes, so it wastes bytes * hi / (2 * B) bytes. Divided by the total
+	   bytes, the sum over classes estimates WCR.
+	2. Seal rate: a class that seals rarely keeps its open box in RAM for long. The fraction of the trace
+	   between two seals is B / bytes, capped at 1. It is weighted by granSealWeight.
+	The cost is additive over classes, so dynamic programming finds the optimum. Simulated annealing searches
+	the same cost and is kept for comparison.
+ */
+
+const granBucketsPerDecade = 100
+
+type sizeBucket struct {
+	edge		int64		// largest size in the bucket
+	count		int64
+	bytes		int64
+}
+
+var (
+	granSealWeight		float64 = 0.1
+	granAnnealSteps		int = 200000
+)
+
+/**
+	Weight of the seal rate against fragmentation. 0 --> only fragmentation is minimized.
+ */
+func SetSealWeight(weight float64) {
+	granSealWeight = weight
+}
+
+/**
+	Read the size histogram (size --> number of requests) of a trace.
+ */
+func SizeHistogram(filePath string) map[int64]int64 {
+	file, err := os.Open(filePath)
+	if err != nil {
+		log.Fatalf("Cannot open file %s --> %s.\n", filePath, err)
+	}
+	defer file.Close()
+
+	histogram := make(map[int64]int64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record, ok := Chunk.ParseRecord(scanner.Text())
+		if !ok {
+			continue
+		}
+		histogram[record.Size]++
+	}
+	return histogram
+}
+
+/**
+	Return "number" upper bounds minimizing the estimated cost, to be passed to StartUpWithGranularity.
+	method: "DP" (dynamic programming) or "SA" (simulated annealing).
+	If classes have their own box sizes, SetClassBoxSizes should be called first.
+ */
+func OptimalGranularity(histogram map[int64]int64, number int, objSize int64, method string) []int64 {
+	if number < 1 {
+		log.Fatalf("Need at least one size class, got %d.\n", number)
+	}
+	buckets := granBuckets(histogram, objSize)
+	if len(buckets) == 0 {
+		log.Fatalf("No object size in the histogram fits the maximum object size %d.\n", objSize)
+	}
+	if len(buckets) < number {
+		DFmtPrintf("OptimalGranularity:: only %d distinct size buckets for %d classes.\n", len(buckets), number)
+		number = len(buckets)
+	}
+	prefix := make([]int64, len(buckets) + 1)
+	for index, bucket := range buckets {
+		prefix[index + 1] = prefix[index] + bucket.bytes
+	}
+
+	var cuts []int
+	switch method {
+	case "DP":
+		cuts = granDP(buckets, prefix, number, objSize)
+	case "SA":
+		cuts = granAnneal(buckets, prefix, number, objSize)
+	default:
+		log.Fatalf("Wrong granularity search method %s. Should be DP or SA.\n", method)
+	}
+
+	bounds := make([]int64, number)
+	for class, cut := range cuts {
+		bounds[class] = buckets[cut].edge
+	}
+	bounds[number - 1] = objSize
+	DFmtPrintf("OptimalGranularity:: method: %s, bounds: %v, cost: %f.\n",
+		method, bounds, granCost(buckets, prefix, cuts, objSize))
+	return bounds
+}
+
+func granBuckets(histogram map[int64]int64, objSize int64) []sizeBucket {
+	byEdge := make(map[int64]*sizeBucket)
+	for size, number := range histogram {
+		if size <= 0 || size > objSize {
+			continue
+		}
+		power := math.Ceil(math.Log10(float64(size)) * granBucketsPerDecade) / granBucketsPerDecade
+		edge := int64(math.Ceil(math.Pow(10, power)))
+		if edge < size {
+			edge = size
+		}
+		if edge > objSize {
+			edge = objSize
+		}
+		bucket, ok := byEdge[edge]
+		if !ok {
+			bucket = &sizeBucket{edge: edge}
+			byEdge[edge] = bucket
+		}
+		bucket.count += number
+		bucket.bytes += size * number
+	}
+
+	buckets := make([]sizeBucket, 0, len(byEdge))
+	for _, bucket := range byEdge {
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].edge < buckets[j].edge })
+	return buckets
+}
+
+/**
+	Cost of the class holding buckets [first, last].
+ */
+func granClassCost(buckets []sizeBucket, prefix []int64, first int, last int, class int, number int,
+	objSize int64) float64 {
+	bytes := prefix[last + 1] - prefix[first]
+	if bytes == 0 {
+		return granSealWeight
+	}
+	upperBound := buckets[last].edge
+	if class == number - 1 {
+		upperBound = objSize
+	}
+	boxSize := float64(classBoxSize(class))
+	frag := float64(bytes) * math.Min(float64(upperBound), boxSize) / (2 * boxSize) / float64(prefix[len(buckets)])
+	idle := math.Min(1, boxSize / float64(bytes))
+	return frag + granSealWeight * idle
+}
+
+/**
+	Total cost. cuts[class] is the last bucket of the class.
+ */
+func granCost(buckets []sizeBucket, prefix []int64, cuts []int, objSize int64) float64 {
+	cost := 0.0
+	first := 0
+	for class, last := range cuts {
+		cost += granClassCost(buckets, prefix, first, last, class, len(cuts), objSize)
+		first = last + 1
+	}
+	return cost
+}
+
+/**
+	cost[class][last]: minimum cost of classes 0 ~ class holding buckets 0 ~ last.
+ */
+func granDP(buckets []sizeBucket, prefix []int64, number int, objSize int64) []int {
+	n := len(buckets)
+	cost := make([][]float64, number)
+	parent := make([][]int, number)
+	for class := 0; class < number; class++ {
+		cost[class] = make([]float64, n)
+		parent[class] = make([]int, n)
+		for last := range cost[class] {
+			cost[class][last] = math.Inf(1)
+		}
+	}
+	for last := 0; last < n; last++ {
+		cost[0][last] = granClassCost(buckets, prefix, 0, last, 0, number, objSize)
+	}
+	for class := 1; class < number; class++ {
+		for last := class; last < n; last++ {
+			if class == number - 1 && last != n - 1 {
+				continue
+			}
+			for prev := class - 1; prev < last; prev++ {
+				curr := cost[class - 1][prev] + granClassCost(buckets, prefix, prev + 1, last, class, number, objSize)
+				if curr < cost[class][last] {
+					cost[class][last] = curr
+					parent[class][last] = prev
+				}
+			}
+		}
+	}
+
+	cuts := make([]int, number)
+	cuts[number - 1] = n - 1
+	for class := number - 1; class > 0; class-- {
+		cuts[class - 1] = parent[class][cuts[class]]
+	}
+	return cuts
+}
+
+/**
+	Start from classes with the same number of buckets and move one cut at a time.
+ */
+func granAnneal(buckets []sizeBucket, prefix []int64, number int, objSize int64) []int {
+	n := len(buckets)
+	random := newRunState(seed).source("granularity")
+	cuts := make([]int, number)
+	for class := range cuts {
+		cuts[class] = (class + 1) * n / number - 1
+	}
+	curr := granCost(buckets, prefix, cuts, objSize)
+	best := append([]int(nil), cuts...)
+	bestCost := curr
+	if number == 1 {
+		return best
+	}
+
+	temperature := curr * 0.1
+	cooling := math.Pow(1e-6, 1 / float64(granAnnealSteps))
+	for step := 0; step < granAnnealSteps; step++ {
+		class := random.Intn(number - 1)
+		low := -1
+		if class > 0 {
+			low = cuts[class - 1]
+		}
+		high := cuts[class + 1]
+		if high - low <= 2 {
+			temperature *= cooling
+			continue
+		}
+		old := cuts[class]
+		cuts[class] = low + 1 + random.Intn(high - low - 1)
+		next := granCost(buckets, prefix, cuts, objSize)
+		if next <= curr || random.Float64() < math.Exp((curr - next) / temperature) {
+			curr = next
+			if curr < bestCost {
+				bestCost = curr
+				copy(best, cuts)
+			}
+		} else {
+			cuts[class] = old
+		}
+		temperature *= cooling
+	}
+	return best
+}
@@ -0,0 +1,71 @@
+package ObjectBased
+
+import (
+	"math"
+	"testing"
+)
+
+/**
+	Histogram with sizes spread over four decades and more bytes in a few hot sizes.
+ */
+func testHistogram() map[int64]int64 {
+	histogram := make(map[int64]int64)
+	for id := 0; id < testObjects; id++ {
+		histogram[testSize(id) + int64(id % 7)]++
+	}
+	histogram[300] += 2000
+	histogram[5000] += 500
+	return histogram
+}
+
+func TestGranularityDPOptimal(t *testing.T) {
+	SetBoxSize(1 << 20)
+	buckets := granBuckets(testHistogram(), testMaxObjSize)
+	prefix := make([]int64, len(buckets) + 1)
+	for index, bucket := range buckets {
+		prefix[index + 1] = prefix[index] + bucket.bytes
+	}
+	n := len(buckets)
+	dpCost := granCost(buckets, prefix, granDP(buckets, prefix, 3, testMaxObjSize), testMaxObjSize)
+
+	bestCost := math.Inf(1)
+	for first := 0; first < n - 2; first++ {
+		for second := first + 1; second < n - 1; second++ {
+			bestCost = math.Min(bestCost, granCost(buckets, prefix, []int{first, second, n - 1}, testMaxObjSize))
+		}
+	}
+	if math.Abs(dpCost - bestCost) > 1e-12 {
+		t.Fatalf("DP cost %f, exhaustive search cost %f", dpCost, bestCost)
+	}
+
+	saCost := granCost(buckets, prefix, granAnneal(buckets, prefix, 3, testMaxObjSize), testMaxObjSize)
+	if saCost < bestCost - 1e-12 || saCost > bestCost * 1.05 {
+		t.Fatalf("SA cost %f, optimum %f", saCost, bestCost)
+	}
+}
+
+func TestOptimalGranularityBounds(t *testing.T) {
+	SetBoxSize(1 << 20)
+	for _, method := range []string{"DP", "SA"} {
+		bounds := OptimalGranularity(testHistogram(), 4, testMaxObjSize, method)
+		if len(bounds) != 4 || bounds[3] != testMaxObjSize {
+			t.Fatalf("%s: bounds %v", method, bounds)
+		}
+		for class := 1; class < len(bounds); class++ {
+			if bounds[class] <= bounds[class - 1] {
+				t.Fatalf("%s: bounds %v are not increasing", method, bounds)
+			}
+		}
+	}
+	// fewer size buckets than classes
+	if bounds := OptimalGranularity(map[int64]int64{100: 5, 200: 5}, 4, testMaxObjSize, "DP"); len(bounds) != 2 {
+		t.Fatalf("bounds %v for two sizes", bounds)
+	}
+}
+
+func TestSizeHistogram(t *testing.T) {
+	histogram := SizeHistogram(writeTrace(t, []string{"a 100", "b 100", "c 200 0 99", "bad"}))
+	if len(histogram) != 2 || histogram[100] != 2 || histogram[200] != 1 {
+		t.Fatalf("histogram %v", histogram)
+	}
+}
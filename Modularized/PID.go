@@ -0,0 +1,108 @@
+package ObjectBased
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+/**
+	Feedback admission (model "PID"). A PID controller sets the admission output u in [0, 1] so that the bytes
+	written into flash per quantum of writeBudget converge on pidSetpoint. Written bytes are the used bytes of
+	writeBudget, so they follow its charging mode (per object or at seal time). At the end of every quantum:
+		e = (setpoint - written) / setpoint
+		u += Kp * (e - e1) + Ki * e + Kd * (e - 2 * e1 + e2)
+	This is the velocity form, so clamping u is enough to stop integral windup.
+	u is used in one of two ways:
+	1. prob: a missed object is admitted with probability u.
+	2. size: a missed object is admitted if it is not larger than maxObjSize ^ u.
+ */
+
+type PIDStep struct {
+	Request			int64
+	Setpoint		int64
+	Written			int64
+	Error			float64
+	Output			float64
+}
+
+var (
+	pidSetpoint			int64			// target bytes written per quantum
+	pidKp				float64
+	pidKi				float64
+	pidKd				float64
+	pidMode				string
+	pidOutput			float64
+	pidErrors			[2]float64		// errors of the last two quanta
+	pidQuantum			int64			// last quantum of writeBudget seen by PID
+	PIDLog				[]PIDStep		// one step per quantum
+)
+
+/**
+	Set up PID admission. Should be called after StartUp and WriteBudgetSetUp, quanta are those of writeBudget.
+	setpoint: target bytes written per quantum. mode: "prob" or "size".
+ */
+func PIDSetUp(setpoint int64, kp float64, ki float64, kd float64, mode string) {
+	if setpoint <= 0 {
+		log.Fatalf("Wrong PID configuration: setpoint %d.\n", setpoint)
+	}
+	if mode != "prob" && mode != "size" {
+		log.Fatalf("Wrong PID mode %s. Should be prob or size.\n", mode)
+	}
+	pidSetpoint = setpoint
+	pidKp = kp
+	pidKi = ki
+	pidKd = kd
+	pidMode = mode
+	pidOutput = 1		// admit everything at the beginning
+	pidErrors = [2]float64{}
+	pidQuantum = writeBudget.quanta
+	PIDLog = make([]PIDStep, 0)
+}
+
+/**
+	When a quantum of writeBudget finishes, update the output from the bytes written in it.
+ */
+func updatePID() {
+	if writeBudget.quanta == pidQuantum {
+		return
+	}
+	pidQuantum = writeBudget.quanta
+	written := writeBudget.lastUsed
+	e := float64(pidSetpoint - written) / float64(pidSetpoint)
+	e1, e2 := pidErrors[0], pidErrors[1]
+	pidOutput += pidKp * (e - e1) + pidKi * e + pidKd * (e - 2 * e1 + e2)
+	pidOutput = math.Max(0, math.Min(1, pidOutput))
+	pidErrors = [2]float64{e, e1}
+
+	PIDLog = append(PIDLog, PIDStep{numRequest, pidSetpoint, written, e, pidOutput})
+	DFmtPrintf("updatePID:: request: %d, setpoint: %d, written: %d, error: %f, output: %f.\n",
+		numRequest, pidSetpoint, written, e, pidOutput)
+}
+
+func admissionPID(size int64) bool {
+	updatePID()
+	var admit bool
+	if pidMode == "size" {
+		admit = float64(size) <= math.Pow(float64(maxObjSize), pidOutput)
+	} else {
+		admit = run.random.Float64() < pidOutput
+	}
+	return admit
+}
+
+/**
+	Return the controller log and print the mean absolute error over all quanta.
+ */
+func GetPIDResults() []PIDStep {
+	meanError := 0.0
+	for _, step := range PIDLog {
+		meanError += math.Abs(step.Error)
+	}
+	if len(PIDLog) > 0 {
+		meanError /= float64(len(PIDLog))
+	}
+	fmt.Printf("PID:: mode: %s, quanta: %d, setpoint: %d, mean absolute error: %f, output: %f.\n",
+		pidMode, len(PIDLog), pidSetpoint, meanError, pidOutput)
+	return PIDLog
+}
@@ -0,0 +1,54 @@
+package ObjectBased
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPIDStep(t *testing.T) {
+	testStartUp(t)
+	WriteBudgetSetUp(1000, unlimited, 0, 10)
+	PIDSetUp(1000, 0.5, 0.1, 0, "prob")
+	writeBudget.tick()
+	// three times the setpoint is written, the output drops below 0 and is clamped
+	writeBudget.chargeAdmission(3000)
+	tickTo(writeBudget, 10)
+	updatePID()
+	updatePID()
+	// nothing is written in the next quantum
+	tickTo(writeBudget, 20)
+	updatePID()
+	expected := []PIDStep{{10, 1000, 3000, -2, 0}, {20, 1000, 0, 1, 1}}
+	if len(PIDLog) != len(expected) {
+		t.Fatalf("%d steps logged, expected %d", len(PIDLog), len(expected))
+	}
+	for index := range expected {
+		if PIDLog[index] != expected[index] {
+			t.Fatalf("step %d: %v, expected %v", index, PIDLog[index], expected[index])
+		}
+	}
+}
+
+func TestPIDTracksSetpoint(t *testing.T) {
+	defer SetSealCharging(false)
+	for _, mode := range []string{"prob", "size", "sealed"} {
+		SetSealCharging(mode == "sealed")
+		testStartUp(t)
+		WriteBudgetSetUp(unlimited, unlimited, 0, 1000)
+		if mode == "sealed" {
+			PIDSetUp(1 << 20, 0.05, 0.02, 0, "prob")
+		} else {
+			PIDSetUp(1 << 20, 0.05, 0.02, 0, mode)
+		}
+		replay("PID", 60000)
+		steps := GetPIDResults()
+		var written int64
+		for _, step := range steps[len(steps) / 2:] {
+			written += step.Written
+		}
+		mean := float64(written) / float64(len(steps) - len(steps) / 2)
+		if math.Abs(mean - (1 << 20)) > 0.1 * (1 << 20) {
+			t.Fatalf("%s: %f bytes written per quantum in the second half, setpoint %d", mode, mean, 1 << 20)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package ObjectBased
+
+import (
+	"container/list"
+	"fmt"
+	"log"
+	"sort"
+)
+
+/**
+	Partitioned box cache (box eviction PARTITION). Every size class owns a share of the flash and an LRU queue
+	of its sealed boxes, so a burst in one class can only evict boxes of that class. Shares start equal.
+	Every partitionInterval requests the rebalancer moves one box slot from the class with the lowest marginal
+	hit gain to the class with the highest, like the slab automover of memcached. The marginal gain of a class
+	is measured by its ghost queue: the ids of the objects in its last partitionGhostBoxes evicted boxes.
+	A miss found in the ghost queue would have been a hit with that many more boxes.
+	After the size classes change, the share of every old class is carried into the new classes covering its
+	range of sizes, so the work of the rebalancer is kept. Partitions of old classes have no share left and give
+	their boxes back first.
+ */
+
+type partition struct {
+	bound			int64
+	queue			*list.List			// sealed boxes, LRU in the front
+	used			int64
+	capacity		int64
+	ghost			*list.List			// ids of the objects of evicted boxes, oldest box in the front
+	ghostIndex		map[string]int		// object id --> number of ghost boxes holding it
+	ghostHits		int64				// in the current window
+
+	/* experiment part */
+	totalGhostHits	int64
+}
+
+var (
+	partitionInterval		int64 = 100000
+	partitionGhostBoxes		= 1
+	partitions				map[int64]*partition
+	lastRebalance			int64
+
+	/* experiment part */
+	slotMoves				int64
+)
+
+/**
+	Set how often (in requests) one slot is moved, and how many evicted boxes each ghost queue remembers.
+	Should be called before StartUp.
+ */
+func SetPartitionRebalance(interval int64, ghostBoxes int) {
+	if interval <= 0 || ghostBoxes < 1 {
+		log.Fatalf("Wrong partition rebalance: interval %d, ghost boxes %d.\n", interval, ghostBoxes)
+	}
+	partitionInterval = interval
+	partitionGhostBoxes = ghostBoxes
+}
+
+func partitionSetUp() {
+	partitions = make(map[int64]*partition)
+	lastRebalance = 0
+	slotMoves = 0
+	partitionSetShares()
+}
+
+func getPartition(bound int64) *partition {
+	part, ok := partitions[bound]
+	if !ok {
+		part = &partition{
+			bound:		bound,
+			queue:		list.New(),
+			ghost:		list.New(),
+			ghostIndex:	make(map[string]int),
+		}
+		partitions[bound] = part
+	}
+	return part
+}
+
+/**
+	Split the flash equally among the current size classes.
+ */
+func partitionSetShares() {
+	for _, part := range partitions {
+		part.capacity = 0
+	}
+	share := flashCapacity() / int64(len(granularity))
+	for _, bound := range granularity {
+		getPartition(bound).capacity = share
+	}
+}
+
+/**
+	Carry the shares of the old size classes into the new ones. The share of an old class is split equally among
+	the new classes which overlap its range of sizes, so the total share stays the same.
+ */
+func partitionCarryShares(oldBounds []int64, bounds []int64) {
+	shares := make(map[int64]int64, len(bounds))
+	var oldLow int64
+	for _, oldBound := range oldBounds {
+		covering := make([]int64, 0, 1)
+		var low int64
+		for _, bound := range bounds {
+			if bound > oldLow && low < oldBound {
+				covering = append(covering, bound)
+			}
+			low = bound
+		}
+		if len(covering) == 0 {
+			covering = bounds[len(bounds) - 1:]
+		}
+		share := getPartition(oldBound).capacity
+		for index, bound := range covering {
+			shares[bound] += share / int64(len(covering))
+			if index == 0 {
+				shares[bound] += share % int64(len(covering))
+			}
+		}
+		oldLow = oldBound
+	}
+
+	for _, part := range partitions {
+		part.capacity = 0
+	}
+	for bound, share := range shares {
+		getPartition(bound).capacity = share
+	}
+}
+
+/**
+	Add a newly sealed box into the partition of its class. Partitions over their share are shrunk first.
+ */
+func partitionInsert(box *Box) {
+	for _, part := range partitions {
+		for part.used > part.capacity && part.queue.Len() > 0 {
+			partitionEvict(part)
+		}
+	}
+	part := getPartition(box.upperBound)
+	for part.used + box.maxSize > part.capacity && part.queue.Len() > 0 {
+		partitionEvict(part)
+	}
+	part.queue.PushBack(box)
+	part.used += box.maxSize
+	boxQueueMap[box.boxId] = &QueuePos{part.queue.Back(), true}
+}
+
+func partitionHit(boxId int64) {
+	pos := boxQueueMap[boxId]
+	box := pos.element.Value.(*Box)
+	part := partitions[box.upperBound]
+	part.queue.MoveToBack(pos.element)
+}
+
+func partitionEvict(part *partition) {
+	box := part.queue.Remove(part.queue.Front()).(*Box)
+	part.used -= box.maxSize
+
+	ids := make([]string, 0, len(box.objOffsetMap))
+	for id := range box.objOffsetMap {
+		if boxId, ok := cachedObj[id]; ok && boxId == box.boxId {
+			ids = append(ids, id)
+			part.ghostIndex[id]++
+		}
+	}
+	part.ghost.PushBack(ids)
+	for part.ghost.Len() > partitionGhostBoxes {
+		for _, id := range part.ghost.Remove(part.ghost.Front()).([]string) {
+			part.ghostIndex[id]--
+			if part.ghostIndex[id] == 0 {
+				delete(part.ghostIndex, id)
+			}
+		}
+	}
+	evictBox(box)
+}
+
+/**
+	A miss in the size class: check its ghost queue and rebalance when the window is over.
+ */
+func partitionMiss(bound int64, id string) {
+	if part, ok := partitions[bound]; ok {
+		if _, ok := part.ghostIndex[id]; ok {
+			part.ghostHits++
+			part.totalGhostHits++
+		}
+	}
+	if numRequest - lastRebalance >= partitionInterval {
+		rebalance()
+		lastRebalance = numRequest
+	}
+}
+
+/**
+	Move one slot from the current class with the fewest ghost hits to the one with the most.
+ */
+func rebalance() {
+	var donor, receiver *partition
+	for _, bound := range granularity {
+		part := partitions[bound]
+		if receiver == nil || part.ghostHits > receiver.ghostHits {
+			receiver = part
+		}
+	}
+	if receiver == nil {
+		return
+	}
+	slot := boxSizeFor(receiver.bound)
+	for _, bound := range granularity {
+		part := partitions[bound]
+		if part == receiver || part.capacity < slot {
+			continue
+		}
+		if donor == nil || part.ghostHits < donor.ghostHits {
+			donor = part
+		}
+	}
+
+	if donor != nil && receiver.ghostHits > donor.ghostHits {
+		donor.capacity -= slot
+		receiver.capacity += slot
+		for donor.used > donor.capacity && donor.queue.Len() > 0 {
+			partitionEvict(donor)
+		}
+		slotMoves++
+		DPrintf("rebalance:: move one slot from class %d to class %d.\n", donor.bound, receiver.bound)
+	}
+	for _, part := range partitions {
+		part.ghostHits = 0
+	}
+}
+
+/**
+	Return the capacity share of every partition, and print used bytes and ghost hits.
+ */
+func GetPartitionResults() map[int64]int64 {
+	bounds := make([]int64, 0, len(partitions))
+	for bound := range partitions {
+		bounds = append(bounds, bound)
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	result := make(map[int64]int64)
+	fmt.Printf("Partitions:: slot moves: %d.\n", slotMoves)
+	for _, bound := range bounds {
+		part := partitions[bound]
+		result[bound] = part.capacity
+		fmt.Printf("class %d: capacity: %d, used: %d, boxes: %d, ghost hits: %d.\n",
+			bound, part.capacity, part.used, part.queue.Len(), part.totalGhostHits)
+	}
+	return result
+}
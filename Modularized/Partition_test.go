@@ -0,0 +1,72 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestPartitionSharesBeforeRepacking(t *testing.T) {
+	SetBoxEviction("PARTITION")
+	defer SetBoxEviction("S2LRU")
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	// almost full open boxes in three classes, about three boxes together
+	for id := 0; id < 2; id++ {
+		Request("l" + strconv.Itoa(id), "500000", "lameDuck")
+	}
+	for id := 0; id < 52; id++ {
+		Request("m" + strconv.Itoa(id), "20000", "lameDuck")
+	}
+	for id := 0; id < 1040; id++ {
+		Request("s" + strconv.Itoa(id), "1000", "lameDuck")
+	}
+	if numSeal != 0 {
+		t.Fatalf("%d boxes sealed before the size classes change", numSeal)
+	}
+
+	// merge the three upper classes into one new class, which takes their shares
+	updateGranularity([]int64{32, 2 * testMaxObjSize})
+	part := partitions[2 * testMaxObjSize]
+	if numSeal != 2 || part.queue.Len() != 2 {
+		t.Fatalf("%d boxes sealed, %d in the new partition, expected 2", numSeal, part.queue.Len())
+	}
+	if part.capacity != flashCapacity() * 3 / 4 || part.used > part.capacity {
+		t.Fatalf("new partition uses %d of %d bytes", part.used, part.capacity)
+	}
+}
+
+func TestPartitionSharesCarried(t *testing.T) {
+	SetBoxEviction("PARTITION")
+	defer SetBoxEviction("S2LRU")
+	testStartUp(t)
+	// shares after some rebalancing, in MB
+	for bound, share := range map[int64]int64{32: 8, 1024: 24, 32768: 16, testMaxObjSize: 16} {
+		partitions[bound].capacity = share << 20
+	}
+	// class 1024 is split in two, classes 32768 and 1 MB are merged
+	updateGranularity([]int64{32, 512, 1024, testMaxObjSize})
+	for bound, share := range map[int64]int64{32: 8, 512: 12, 1024: 12, testMaxObjSize: 32} {
+		if partitions[bound].capacity != share << 20 {
+			t.Fatalf("class %d has a share of %d bytes, expected %d MB", bound, partitions[bound].capacity, share)
+		}
+	}
+	if partitions[32768].capacity != 0 {
+		t.Fatalf("old class 32768 keeps a share of %d bytes", partitions[32768].capacity)
+	}
+}
+
+func TestPartitionEvictsWithinClass(t *testing.T) {
+	SetBoxEviction("PARTITION")
+	defer SetBoxEviction("S2LRU")
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	replay("lameDuck", 30000)
+	for bound, part := range partitions {
+		if part.used > part.capacity {
+			t.Fatalf("partition %d uses %d of %d bytes", bound, part.used, part.capacity)
+		}
+	}
+	if GetPartitionResults(); slotMoves != 0 {
+		t.Fatalf("%d slots moved before the first rebalance interval", slotMoves)
+	}
+}
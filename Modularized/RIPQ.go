@@ -0,0 +1,285 @@
+package ObjectBased
+
+import (
+	"container/list"
+	"log"
+	"math"
+	"strconv"
+)
+
+/**
+	RIPQ: restricted insertion priority queue (Tang et al., NSDI 2015).
+	The flash is one queue of sealed boxes (device blocks) split into K sections. Section 0 is the tail
+	(evicted first) and section K-1 is the head. Every section has one open box (active device block) in RAM,
+	so an object can only be inserted at one of K insertion points.
+	A hit never moves data. The object is only added to the virtual block of its new section, which counts
+	towards the section size. When the box holding it is evicted from the tail, the object is reinserted into
+	the active block of its virtual section. Other objects in that box are evicted.
+	Sections are kept at capacity / K: when a section grows too large, its oldest box moves down one section.
+
+	Priority policies:
+	1. SLRU: misses are inserted at section 0 and every hit promotes the object by one section.
+	2. GDSF: the section is chosen by frequency / size, relative to the average object size seen so far.
+ */
+
+type ripqItem struct {
+	size		int64
+	box			*Box		// where the object is physically stored
+	virtual		int			// section of its virtual block, -1 --> none
+	freq		int
+}
+
+var (
+	ripqK				int
+	ripqPolicy			string
+	ripqCapacity		int64
+	ripqSections		[]*list.List		// section --> sealed boxes, oldest in the front
+	ripqActive			[]*Box				// section --> open box
+	ripqVirtual			[]int64				// section --> bytes in virtual block
+	ripqBoxSection		map[int64]int		// box id --> section
+	ripqItems			map[string]*ripqItem
+	ripqSealed			int64				// number of sealed boxes in flash
+
+	/* experiment part */
+	ripqReinsertBytes	int64				// bytes rewritten at eviction
+	ripqEvictedBytes	int64
+)
+
+/**
+	Set up RIPQ with the flash size (Bytes), the number of insertion points and the priority policy.
+ */
+func RIPQSetUp(cacheSize int64, k int, policy string) {
+	if k < 1 {
+		log.Fatalf("RIPQ needs at least one insertion point, got %d.\n", k)
+	}
+	if policy != "SLRU" && policy != "GDSF" {
+		log.Fatalf("Wrong RIPQ policy %s. Should be SLRU or GDSF.\n", policy)
+	}
+	ripqK = k
+	ripqPolicy = policy
+	ripqCapacity = cacheSize
+	ripqSections = make([]*list.List, k)
+	ripqActive = make([]*Box, k)
+	ripqVirtual = make([]int64, k)
+	ripqBoxSection = make(map[int64]int)
+	ripqItems = make(map[string]*ripqItem)
+	ripqSealed = 0
+	ripqReinsertBytes = 0
+	ripqEvictedBytes = 0
+	nextBoxId = 1
+	writeBudget = nil
+	for section := 0; section < k; section++ {
+		ripqSections[section] = list.New()
+		ripqActive[section] = newRIPQBox(section)
+	}
+
+	basicSetUp()
+	timeSetUp()
+}
+
+func newRIPQBox(section int) *Box {
+	box := &Box{
+		boxId:			nextBoxId,
+		currSize:		0,
+		upperBound:		int64(section),
+		objOffsetMap:	make(map[string]int64),
+		maxSize:		maxBoxSize,
+	}
+	nextBoxId++
+	ripqBoxSection[box.boxId] = section
+	return box
+}
+
+/**
+	Deal with new command. Same trace format as Request. Return true if it is a hit.
+ */
+func RIPQRequest(id string, size string) bool {
+	numRequest++
+	writeBudget.tick()
+	getResultsWithTime()
+
+	object, err := strconv.Atoi(size)
+	objectSize := int64(object)
+	if err != nil {
+		DPrintf("Input size %s cannot be converted to int64 type with error %s.\n", size, err)
+	}
+	reqBytes += objectSize
+	if objectSize > maxBoxSize {
+		DPrintf("Object size %s exceeds the maximum box size.\n", size)
+		return false
+	}
+
+	item, ok := ripqItems[id]
+	if ok && item.size == objectSize {
+		hits++
+		hitBytes += objectSize
+		item.freq++
+		ripqSetVirtual(item, ripqHitSection(item))
+		return true
+	}
+	if ok {
+		// out of date --> the old copy becomes garbage
+		ripqDrop(id, item)
+	}
+
+	item = &ripqItem{size: objectSize, virtual: -1, freq: 1}
+	ripqItems[id] = item
+	writeBudget.chargeAdmission(objectSize)
+	ripqInsert(id, item, ripqMissSection(item))
+	return false
+}
+
+/**
+	Current section of the object: its virtual block if any, otherwise the box it is stored in.
+ */
+func ripqSection(item *ripqItem) int {
+	if item.virtual >= 0 {
+		return item.virtual
+	}
+	return ripqBoxSection[item.box.boxId]
+}
+
+func ripqMissSection(item *ripqItem) int {
+	if ripqPolicy == "GDSF" {
+		return ripqGDSFSection(item)
+	}
+	return 0
+}
+
+func ripqHitSection(item *ripqItem) int {
+	if ripqPolicy == "GDSF" {
+		return ripqGDSFSection(item)
+	}
+	section := ripqSection(item) + 1
+	if section >= ripqK {
+		section = ripqK - 1
+	}
+	return section
+}
+
+/**
+	GDSF priority: frequency / size. An object of average size hit 2^n times goes to section n.
+ */
+func ripqGDSFSection(item *ripqItem) int {
+	avgSize := float64(reqBytes) / float64(numRequest)
+	score := float64(item.freq) * avgSize / float64(item.size)
+	section := int(math.Log2(1 + score))
+	if section >= ripqK {
+		section = ripqK - 1
+	}
+	return section
+}
+
+/**
+	Lazy update on hit: only the virtual block of the new section is changed.
+ */
+func ripqSetVirtual(item *ripqItem, section int) {
+	if item.virtual >= 0 {
+		ripqVirtual[item.virtual] -= item.size
+		item.virtual = -1
+	}
+	if section == ripqBoxSection[item.box.boxId] {
+		return
+	}
+	item.virtual = section
+	ripqVirtual[section] += item.size
+}
+
+/**
+	Write the object into the active block of the section. Seal the active block first if it is full.
+ */
+func ripqInsert(id string, item *ripqItem, section int) {
+	box := ripqActive[section]
+	if box.currSize + item.size > maxBoxSize {
+		ripqSeal(section)
+		box = ripqActive[section]
+	}
+	box.objOffsetMap[id] = box.currSize
+	box.currSize += item.size
+	item.box = box
+}
+
+func ripqSeal(section int) {
+	box := ripqActive[section]
+	ripqSections[section].PushBack(box)
+	ripqSealed++
+	writeBudget.chargeSeal(maxBoxSize, maxBoxSize - box.currSize)
+	fragRatio += float64(maxBoxSize - box.currSize) / float64(maxBoxSize)
+	fragBytes += maxBoxSize - box.currSize
+	sealedBytes += maxBoxSize
+	numSeal++
+	ripqActive[section] = newRIPQBox(section)
+
+	ripqBalance()
+	for ripqSealed * maxBoxSize > ripqCapacity {
+		ripqEvict()
+	}
+}
+
+/**
+	Keep every section at capacity / K by moving its oldest box down one section.
+ */
+func ripqBalance() {
+	target := ripqCapacity / int64(ripqK)
+	for section := ripqK - 1; section > 0; section-- {
+		blocks := ripqSections[section]
+		for blocks.Len() > 0 && int64(blocks.Len()) * maxBoxSize + ripqVirtual[section] > target {
+			box := blocks.Remove(blocks.Front()).(*Box)
+			ripqSections[section - 1].PushBack(box)
+			ripqBoxSection[box.boxId] = section - 1
+		}
+	}
+}
+
+/**
+	Evict the box at the tail. Objects with a virtual copy are reinserted, the others leave the cache.
+ */
+func ripqEvict() {
+	section := 0
+	for ripqSections[section].Len() == 0 {
+		section++
+	}
+	box := ripqSections[section].Remove(ripqSections[section].Front()).(*Box)
+	ripqSealed--
+	delete(ripqBoxSection, box.boxId)
+
+	reinsert := make([]string, 0)
+	for id := range box.objOffsetMap {
+		item, ok := ripqItems[id]
+		if !ok || item.box != box {
+			continue
+		}
+		if item.virtual >= 0 {
+			reinsert = append(reinsert, id)
+		} else {
+			delete(ripqItems, id)
+			ripqEvictedBytes += item.size
+		}
+	}
+	for _, id := range reinsert {
+		item := ripqItems[id]
+		target := item.virtual
+		ripqVirtual[target] -= item.size
+		item.virtual = -1
+		ripqReinsertBytes += item.size
+		writeBudget.chargeGC(item.size)
+		ripqInsert(id, item, target)
+	}
+}
+
+func ripqDrop(id string, item *ripqItem) {
+	if item.virtual >= 0 {
+		ripqVirtual[item.virtual] -= item.size
+	}
+	delete(ripqItems, id)
+}
+
+/**
+	Return RIPQ specific results: bytes reinserted at eviction and bytes evicted.
+	Hit ratios and seal counts are returned by GetResults.
+ */
+func GetRIPQResults() (int64, int64) {
+	DFmtPrintf("RIPQ:: K: %d, policy: %s, reinserted bytes: %d, evicted bytes: %d.\n",
+		ripqK, ripqPolicy, ripqReinsertBytes, ripqEvictedBytes)
+	return ripqReinsertBytes, ripqEvictedBytes
+}
@@ -0,0 +1,75 @@
+package ObjectBased
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestRIPQReinsertsOnEviction(t *testing.T) {
+	// 4 boxes of 2 objects each, 2 sections
+	SetBoxSize(1000)
+	defer SetBoxSize(1 << 20)
+	RIPQSetUp(4000, 2, "SLRU")
+	RIPQRequest("a", "500")
+	RIPQRequest("b", "500")
+	if !RIPQRequest("a", "500") || ripqItems["a"].virtual != 1 || ripqVirtual[1] != 500 {
+		t.Fatalf("hit on a does not move it into the virtual block of section 1")
+	}
+	// the fifth seal evicts the box holding a and b
+	for index := 0; index < 10; index++ {
+		RIPQRequest("o" + strconv.Itoa(index), "500")
+	}
+	if _, ok := ripqItems["b"]; ok {
+		t.Fatalf("b was not hit, but is still cached")
+	}
+	item, ok := ripqItems["a"]
+	if !ok || item.virtual != -1 || ripqBoxSection[item.box.boxId] != 1 || ripqVirtual[1] != 0 {
+		t.Fatalf("a is not rewritten into the active block of section 1")
+	}
+	if reinserted, evicted := GetRIPQResults(); reinserted != 500 || evicted != 500 {
+		t.Fatalf("%d bytes reinserted and %d bytes evicted, expected 500 each", reinserted, evicted)
+	}
+	if !RIPQRequest("a", "500") || RIPQRequest("b", "500") {
+		t.Fatalf("a should hit and b should miss")
+	}
+}
+
+func TestRIPQPromotionCapped(t *testing.T) {
+	SetBoxSize(1000)
+	defer SetBoxSize(1 << 20)
+	RIPQSetUp(4000, 3, "SLRU")
+	RIPQRequest("a", "500")
+	for hit := 1; hit <= 4; hit++ {
+		RIPQRequest("a", "500")
+		expected := hit
+		if expected > 2 {
+			expected = 2
+		}
+		if section := ripqSection(ripqItems["a"]); section != expected {
+			t.Fatalf("after %d hits a is in section %d, expected %d", hit, section, expected)
+		}
+	}
+	if ripqVirtual[0] + ripqVirtual[1] + ripqVirtual[2] != 500 {
+		t.Fatalf("virtual blocks hold %v bytes, expected 500", ripqVirtual)
+	}
+}
+
+func TestRIPQWithinCapacity(t *testing.T) {
+	for _, policy := range []string{"SLRU", "GDSF"} {
+		SetBoxSize(1 << 20)
+		RIPQSetUp(16 << 20, 4, policy)
+		random := rand.New(rand.NewSource(7))
+		zipf := rand.NewZipf(random, 1.1, 1, testObjects - 1)
+		for index := 0; index < 20000; index++ {
+			id := int(zipf.Uint64())
+			RIPQRequest(strconv.Itoa(id), strconv.FormatInt(testSize(id), 10))
+		}
+		if ripqSealed * maxBoxSize > ripqCapacity {
+			t.Fatalf("%s: %d sealed boxes exceed the capacity", policy, ripqSealed)
+		}
+		if hits == 0 || numSeal <= ripqSealed {
+			t.Fatalf("%s: %d hits, %d seals, %d boxes in flash", policy, hits, numSeal, ripqSealed)
+		}
+	}
+}
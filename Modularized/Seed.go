@@ -0,0 +1,142 @@
+package ObjectBased
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+)
+
+/**
+	All randomness of a run comes from its RunState, created from seed at StartUp, so a run is reproducible from
+	its seed alone. The admission policies draw from the stream of the run. Every other stochastic component
+	(AdaptSize, the annealing of OptimalGranularity) gets its own stream derived from the seed and its name,
+	so the components never consume each other's numbers. RunReplicas repeats a run with different seeds and
+	reports the mean and the 95% confidence interval of OHR, BHR and bytes written.
+ */
+
+type RunState struct {
+	Seed			int64
+	random			*rand.Rand		// admission policies
+}
+
+type Replica struct {
+	Seed			int64
+	OHR				float64
+	BHR				float64
+	BytesWritten	int64
+}
+
+type MetricSummary struct {
+	Mean			float64
+	Low				float64		// 95% confidence interval
+	High			float64
+}
+
+type ReplicaSummary struct {
+	Replicas		[]Replica
+	OHR				MetricSummary
+	BHR				MetricSummary
+	BytesWritten	MetricSummary
+}
+
+// two-sided 95% critical values of Student's t distribution, by degrees of freedom
+var tCritical = []float64{0, 12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042}
+
+var (
+	seed		int64 = 1
+	run			= newRunState(1)
+)
+
+/**
+	Set the seed of the next runs. Should be called before StartUp.
+ */
+func SetSeed(s int64) {
+	seed = s
+}
+
+func seedSetUp() {
+	run = newRunState(seed)
+}
+
+func newRunState(s int64) *RunState {
+	return &RunState{Seed: s, random: rand.New(rand.NewSource(s))}
+}
+
+/**
+	Random stream of one component, which only depends on the seed and the name of the component.
+ */
+func (state *RunState) source(component string) *rand.Rand {
+	hash := fnv.New64a()
+	hash.Write([]byte(component))
+	return rand.New(rand.NewSource(state.Seed ^ int64(hash.Sum64())))
+}
+
+/**
+	Bytes written into flash: sealed boxes, and the Kangaroo log and set pages.
+ */
+func bytesWritten() int64 {
+	return sealedBytes + kLogWrites + kSetWrites * kPageSize
+}
+
+/**
+	Run n replicas with seeds firstSeed, firstSeed + 1, ... experiment should call StartUp and replay the trace.
+ */
+func RunReplicas(n int, firstSeed int64, experiment func()) ReplicaSummary {
+	var summary ReplicaSummary
+	ohr := make([]float64, n)
+	bhr := make([]float64, n)
+	written := make([]float64, n)
+	for index := 0; index < n; index++ {
+		SetSeed(firstSeed + int64(index))
+		experiment()
+		replica := Replica{
+			Seed:			run.Seed,
+			OHR:			float64(hits) / float64(numRequest),
+			BHR:			float64(hitBytes) / float64(reqBytes),
+			BytesWritten:	bytesWritten(),
+		}
+		summary.Replicas = append(summary.Replicas, replica)
+		ohr[index] = replica.OHR
+		bhr[index] = replica.BHR
+		written[index] = float64(replica.BytesWritten)
+	}
+	summary.OHR = summarize(ohr)
+	summary.BHR = summarize(bhr)
+	summary.BytesWritten = summarize(written)
+
+	fmt.Printf("Replicas: %d, seeds: %d ~ %d.\n", n, firstSeed, firstSeed + int64(n) - 1)
+	fmt.Printf("OHR: %f [%f, %f], BHR: %f [%f, %f], bytes written: %.0f [%.0f, %.0f].\n",
+		summary.OHR.Mean, summary.OHR.Low, summary.OHR.High, summary.BHR.Mean, summary.BHR.Low, summary.BHR.High,
+		summary.BytesWritten.Mean, summary.BytesWritten.Low, summary.BytesWritten.High)
+	return summary
+}
+
+func summarize(values []float64) MetricSummary {
+	n := len(values)
+	if n == 0 {
+		return MetricSummary{}
+	}
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(n)
+	if n == 1 {
+		return MetricSummary{mean, mean, mean}
+	}
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	variance /= float64(n - 1)
+	t := 1.96
+	if n - 1 < len(tCritical) {
+		t = tCritical[n - 1]
+	}
+	half := t * math.Sqrt(variance / float64(n))
+	return MetricSummary{mean, mean - half, mean + half}
+}
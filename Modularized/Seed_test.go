@@ -0,0 +1,36 @@
+package ObjectBased
+
+import "testing"
+
+func TestReplicasReproducible(t *testing.T) {
+	defer SetSeed(1)
+	experiment := func() {
+		testStartUp(t)
+		AdmissionSetUp("spicyChicken")
+		replay("spicyChicken", 20000)
+	}
+	first := RunReplicas(3, 5, experiment)
+	second := RunReplicas(3, 5, experiment)
+	for index, replica := range first.Replicas {
+		if replica != second.Replicas[index] {
+			t.Fatalf("replica %d differs: %+v vs %+v", index, replica, second.Replicas[index])
+		}
+	}
+	if first.Replicas[0] == first.Replicas[1] {
+		t.Fatalf("seeds 5 and 6 give the same replica: %+v", first.Replicas[0])
+	}
+	if first.OHR.Low > first.OHR.Mean || first.OHR.High < first.OHR.Mean {
+		t.Fatalf("mean outside its confidence interval: %+v", first.OHR)
+	}
+}
+
+func TestComponentStreamsIndependent(t *testing.T) {
+	a, b := newRunState(3), newRunState(3)
+	a.source("adaptSize").Int63()
+	if a.random.Int63() != b.random.Int63() {
+		t.Fatalf("a component stream consumed the admission stream")
+	}
+	if a.source("adaptSize").Int63() == a.source("granularity").Int63() {
+		t.Fatalf("two components share one stream")
+	}
+}
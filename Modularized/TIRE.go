@@ -2,33 +2,51 @@ package ObjectBased
 
 import (
 	"container/list"
+	"fmt"
 )
 
+/**
+	TIRE admission (model "TIRE"). Quanta, quota and balance are those of writeBudget.
+	Within a quantum, the first interval admits every miss. Each time the written bytes pass another quota,
+	the next interval begins and the threshold goes up by one, so an object is only admitted if it has missed
+	at least threshold times before. Miss counts are kept in a ghost cache,
+	an LRU queue bounded in objects or in bytes. A count is dropped together with its queue entry.
+ */
+
 var(
 	/* TIRE */
 	ghostCache   *GhostCache
-	quota        int64			// quota for each quantum
-	quantum      int			// 5 min --> 1 million requests
 	K            int			// slack variable
 	intervals    []int
 	threshold    int
 	currInterval int
+	tireQuantum		int64		// last quantum of writeBudget seen by TIRE
+
+	/* experiment part */
+	tireAdmits		int64
+	tireRejects		int64
 )
 
 /**
-	Set up the ghost cache.
+	Set up the ghost cache. size is the maximum number of objects, or bytes if inBytes is true.
  */
-func GhoseCacheSetUp(size int64) {
+func ghostCacheSetUp(size int64, inBytes bool) {
 	ghostCache = &GhostCache{
 		accessCount: 	make(map[string]int),
 		currSize: 		0,
-		maxSize: 		size / 8,
+		maxSize: 		size,
+		inBytes:		inBytes,
 		queue:			list.New(),
 		objQueueMap: 	make(map[string]*list.Element),
 	}
 }
 
-func TireSetUp(interval int, k int, bal int64, q int64, quan int) {
+/**
+	Set up TIRE. Should be called after StartUp.
+	interval: number of intervals, k: slack variable.
+	ghostSize: size of the ghost cache, in objects or in bytes if ghostInBytes.
+ */
+func TireSetUp(interval int, k int, ghostSize int64, ghostInBytes bool) {
 	K = k
 	intervals = make([]int, 0)
 	intervals = append(intervals, 1)
@@ -37,25 +55,25 @@ func TireSetUp(interval int, k int, bal int64, q int64, quan int) {
 		intervals = append(intervals, 1 + n * base)
 	}
 	DFmtPrintf("TireSetUp:: intervals: %v.\n", intervals)
-	balance = bal
-	quota = q
-	quantum = quan
-	E = 0
+	tireQuantum = writeBudget.quanta
 	threshold = 0		// admit everything at the beginning
 	currInterval = 1
+	tireAdmits = 0
+	tireRejects = 0
+	ghostCacheSetUp(ghostSize, ghostInBytes)
 }
 
 /**
 	When one quantum finishes, need to calculate balance to determine whether this quantum is allowed to cache some objects
-	Besides, reset the erasure bytes (E) and current interval (to 1)
+	Besides, reset the current interval (to 1)
  */
 func updateTire() {
-	if numRequest % Epoch == 0 {
+	if writeBudget.quanta != tireQuantum {
+		tireQuantum = writeBudget.quanta
 		DFmtPrintf("\n")
-		DFmtPrintf("updateTire:: Number of requests: %d, last quantum: interval: %d, written bytes: %d. ", numRequest, currInterval, E)
-		balance += quota - E
-		DFmtPrintf("Current balance: %d.\n", balance)
-		if balance <= 0 {
+		DFmtPrintf("updateTire:: Number of requests: %d, last quantum: interval: %d, written bytes: %d. ", numRequest, currInterval, writeBudget.lastUsed)
+		DFmtPrintf("Current balance: %d.\n", writeBudget.Allowance())
+		if writeBudget.Allowance() <= 0 {
 			threshold = -1
 			DFmtPrintf("updateTire:: Number of requests: %d. No insertion, wait until next quantum.\n", numRequest)
 		} else {
@@ -67,16 +85,21 @@ func updateTire() {
 				threshold = -1
 			}
 		}
-		// reset erasure bytes and current interval
-		E = 0
+		// reset current interval
 		currInterval = 1
 	}
+	// update current interval and threshold with the bytes written so far
+	for threshold != -1 && writeBudget.Used() > int64(currInterval) * writeBudget.Refill() {
+		currInterval++
+		threshold++
+	}
 }
 
 /**
 	admission control using TIRE --> return whether this object can be admit or not
  */
 func admissionControlTIRE(id string, size int64) bool {
+	updateTire()
 	admit := false
 	if threshold != -1 {
 		// some objects are allowed to cache during this quantum --> check written bytes during this quantum
@@ -84,56 +107,62 @@ func admissionControlTIRE(id string, size int64) bool {
 			admit = true
 		} else {
 			accCount, ok := ghostCache.accessCount[id]
-			if ok {
-				if accCount >= threshold {
-					admit = true
-				} else {
-					admit = false
-				}
-				accCount++
-			} else {
-				accCount = 1
-				admit = false
-			}
-			ghostCache.accessCount[id] = accCount // update access counter
-		}
-
-		// update current interval and threshold
-		if admit {
-			E += size
-			if E > int64(currInterval) * quota {
-				currInterval++
-				threshold++
-			}
+			admit = ok && accCount >= threshold
+			// update access counter before the queue, so a trim can drop it together with the entry
+			ghostCache.accessCount[id] = accCount + 1
+			updateGhostQueue(id, size)
 		}
 	}
+	if admit {
+		tireAdmits++
+	} else {
+		tireRejects++
+	}
 	return admit
 }
 
 func warmUpTIRE(id string, size int64) bool {
-	if numRequest / Epoch < 250 {
+	if numRequest < warmUpRequests {
 		return true
 	}
 	return admissionControlTIRE(id, size)
 }
 
 /**
-	update the LRU list in ghost cache. When ghost cache is full, remove the object in LRU position of the queue.
-	Then remove or add this object to the MRU position of the queue.
+	update the LRU list in ghost cache. Move the object to the MRU position of the queue. When ghost cache is full,
+	remove the objects in LRU position of the queue together with their access counts.
  */
-func updateGhostQueue(id string, exist bool) {
-	if ghostCache.currSize >= ghostCache.maxSize {
-		delete(ghostCache.objQueueMap, ghostCache.queue.Front().Value.(*AccessCount).objectId)
-		ghostCache.queue.Remove(ghostCache.queue.Front())
-		ghostCache.currSize--
+func updateGhostQueue(id string, size int64) {
+	if element, ok := ghostCache.objQueueMap[id]; ok {
+		ghostCache.currSize -= ghostCache.entrySize(element.Value.(*AccessCount))
+		ghostCache.queue.Remove(element)
 	}
+	entry := &AccessCount{objectId: id, objectSize: size}
+	ghostCache.queue.PushBack(entry)
+	ghostCache.objQueueMap[id] = ghostCache.queue.Back()
+	ghostCache.currSize += ghostCache.entrySize(entry)
 
-	if exist {
-		ghostCache.queue.Remove(ghostCache.objQueueMap[id])
-		ghostCache.currSize--
+	for ghostCache.currSize > ghostCache.maxSize && ghostCache.queue.Len() > 0 {
+		front := ghostCache.queue.Remove(ghostCache.queue.Front()).(*AccessCount)
+		ghostCache.currSize -= ghostCache.entrySize(front)
+		delete(ghostCache.objQueueMap, front.objectId)
+		delete(ghostCache.accessCount, front.objectId)
 	}
-	ghostCache.queue.PushBack(&AccessCount{id})
-	ghostCache.objQueueMap[id] = ghostCache.queue.Back()
-	ghostCache.currSize++
 	//DFmtPrintf("updateGhostQueue:: current queue size: %d.\n", ghostCache.queue.Len())
-}
\ No newline at end of file
+}
+
+func (ghost *GhostCache) entrySize(entry *AccessCount) int64 {
+	if ghost.inBytes {
+		return entry.objectSize
+	}
+	return 1
+}
+
+/**
+	Return TIRE results: admitted misses, rejected misses and the number of objects in the ghost cache.
+ */
+func GetTireResults() (int64, int64, int) {
+	fmt.Printf("TIRE:: admits: %d, rejects: %d, balance: %d, ghost objects: %d, ghost counts: %d.\n",
+		tireAdmits, tireRejects, writeBudget.Remaining(), ghostCache.queue.Len(), len(ghostCache.accessCount))
+	return tireAdmits, tireRejects, ghostCache.queue.Len()
+}
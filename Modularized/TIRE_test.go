@@ -0,0 +1,69 @@
+package ObjectBased
+
+import (
+	"strconv"
+	"testing"
+)
+
+/**
+	Set up TIRE past its first interval, so every miss goes through the ghost cache.
+ */
+func tireStartUp(t *testing.T, ghostSize int64, inBytes bool) {
+	testStartUp(t)
+	WriteBudgetSetUp(1000, 1000, 0, 1000000)
+	TireSetUp(4, 9, ghostSize, inBytes)
+	writeBudget.tick()
+	writeBudget.chargeAdmission(5000)
+}
+
+func TestTireGhostBounded(t *testing.T) {
+	tireStartUp(t, 100, false)
+	for id := 0; id < 1000; id++ {
+		admissionControlTIRE(strconv.Itoa(id), 10)
+	}
+	if ghostCache.queue.Len() != 100 || len(ghostCache.accessCount) != 100 {
+		t.Fatalf("ghost holds %d entries and %d counts, expected 100", ghostCache.queue.Len(),
+			len(ghostCache.accessCount))
+	}
+}
+
+func TestTireGhostDropsOversizedCount(t *testing.T) {
+	tireStartUp(t, 1000, true)
+	admissionControlTIRE("large", 5000)
+	if _, ok := ghostCache.accessCount["large"]; ok || ghostCache.queue.Len() != 0 {
+		t.Fatalf("count of an object larger than the ghost is kept")
+	}
+}
+
+func TestTireThresholdAfterQuota(t *testing.T) {
+	tireStartUp(t, 100, false)
+	updateTire()
+	if threshold < 1 {
+		t.Fatalf("threshold %d after using 5 quotas", threshold)
+	}
+	for miss := 0; miss < threshold; miss++ {
+		if admissionControlTIRE("a", 10) {
+			t.Fatalf("admitted on miss %d with threshold %d", miss + 1, threshold)
+		}
+	}
+	if !admissionControlTIRE("a", 10) {
+		t.Fatalf("not admitted after %d misses", threshold)
+	}
+}
+
+func TestTireNoWarmUpCredit(t *testing.T) {
+	testStartUp(t)
+	defer SetWarmUp(0)
+	SetWarmUp(5000)
+	WriteBudgetSetUp(1000, unlimited, unlimited, 100)
+	TireSetUp(4, 9, 100, false)
+	for numRequest < 5001 {
+		numRequest++
+		writeBudget.tick()
+	}
+	warmUpTIRE("a", 10)
+	if writeBudget.Allowance() != 1000 || threshold != 0 {
+		t.Fatalf("allowance %d and threshold %d after warm up, expected 1000 and 0",
+			writeBudget.Allowance(), threshold)
+	}
+}
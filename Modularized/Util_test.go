@@ -0,0 +1,41 @@
+package ObjectBased
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+const (
+	testObjects		= 5000
+	testMaxObjSize	= 1 << 20
+)
+
+/**
+	Start a small cache for tests: 1 MB boxes, no warm up phase and a budget of 4 MB per Epoch.
+ */
+func testStartUp(t *testing.T) {
+	t.Helper()
+	SetBoxSize(1 << 20)
+	SetWarmUp(0)
+	StartUp(64 << 20, 4, testMaxObjSize, 4 << 20)
+}
+
+/**
+	Size of a test object, between 64 Bytes and 256 KB and the same on every request.
+ */
+func testSize(id int) int64 {
+	return 64 << uint(id % 13)
+}
+
+/**
+	Replay a synthetic Zipf trace of n requests. The trace only depends on n.
+ */
+func replay(model string, n int) {
+	random := rand.New(rand.NewSource(7))
+	zipf := rand.NewZipf(random, 1.1, 1, testObjects - 1)
+	for index := 0; index < n; index++ {
+		id := int(zipf.Uint64())
+		Request(strconv.Itoa(id), strconv.FormatInt(testSize(id), 10), model)
+	}
+}
@@ -0,0 +1,242 @@
+package ObjectBased
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// no burst cap and no carry limit
+const unlimited = math.MaxInt64 / 2
+
+/**
+	Flash write budget shared by every admission policy: a token bucket refilled once per quantum.
+	At the end of a quantum, at most carryLimit unused bytes are carried over (a debt is always carried over),
+	the refill is added and the allowance is capped at burst.
+	Everything written to flash draws from it:
+	1. admission: bytes of admitted objects, charged by admission().
+	2. seal: unused bytes at the end of a sealed box, so a box costs its full size.
+	3. GC: bytes rewritten by the cache itself, i.e. RIPQ reinsertions and Kangaroo set pages.
+	With seal charging, 1 and 2 are replaced by the full size of every sealed box, charged when it seals. That is
+	what the device sees: objects still in open boxes, or dropped from them, are never written. Both the
+	per-object bytes and the sealed bytes are always recorded, so the difference can be reported either way.
+	The policies read used bytes and the allowance of the current quantum. They only decide, the bucket never
+	rejects a write itself. Nothing is charged during the warm up phase.
+ */
+
+type TokenBucket struct {
+	refill			int64		// bytes added per quantum
+	burst			int64		// maximum allowance
+	carryLimit		int64		// maximum unused bytes carried over
+	quantum			int64		// requests per quantum
+	allowance		int64		// bytes allowed in the current quantum
+	used			int64		// bytes charged in the current quantum
+	lastUsed		int64		// bytes charged in the last quantum
+	quanta			int64		// finished quanta
+	quantumEnd		int64		// 0 --> not started
+
+	/* experiment part */
+	spent			int64		// bytes charged since the warm up phase
+	admitBytes		int64
+	paddingBytes	int64		// unused bytes of sealed boxes
+	sealBytes		int64		// full size of sealed boxes
+	gcBytes			int64
+	warmUpBytes		int64		// writes during the warm up phase, not charged
+	quantumAdmit	int64		// admitted bytes in the current quantum
+	quantumSeal		int64		// sealed bytes in the current quantum
+	quantumDiff		int64		// sum of |admitted - sealed| over finished quanta
+	maxQuantumDiff	int64
+}
+
+var (
+	warmUpRequests		int64 = 250 * Epoch
+	sealCharging		bool				// charge whole boxes when they seal
+	writeBudget			*TokenBucket
+)
+
+/**
+	Charge the write budget in whole boxes at seal time (true) or per object on admission (false, default).
+ */
+func SetSealCharging(on bool) {
+	sealCharging = on
+}
+
+/**
+	Length of the warm up phase in requests. There is no budget during the warm up phase.
+	Should be called before StartUp.
+ */
+func SetWarmUp(requests int64) {
+	warmUpRequests = requests
+}
+
+func newTokenBucket(initial int64, refill int64, burst int64, carryLimit int64, quantum int64) *TokenBucket {
+	if refill < 0 || burst < 0 || carryLimit < 0 || quantum <= 0 {
+		log.Fatalf("Wrong write budget: refill %d, burst %d, carry limit %d, quantum %d.\n",
+			refill, burst, carryLimit, quantum)
+	}
+	return &TokenBucket{
+		refill:		refill,
+		burst:		burst,
+		carryLimit:	carryLimit,
+		quantum:	quantum,
+		allowance:	initial,
+	}
+}
+
+/**
+	Replace the write budget. refill: bytes per quantum, burst: maximum allowance of one quantum,
+	carry: maximum unused bytes carried into the next quantum, quantum: requests per quantum.
+	Should be called after StartUp.
+ */
+func WriteBudgetSetUp(refill int64, burst int64, carry int64, quantum int64) {
+	initial := refill
+	if initial > burst {
+		initial = burst
+	}
+	writeBudget = newTokenBucket(initial, refill, burst, carry, quantum)
+}
+
+/**
+	Start new quanta when they are due. Called on every request.
+ */
+func (bucket *TokenBucket) tick() {
+	if bucket == nil || numRequest < warmUpRequests {
+		return
+	}
+	if bucket.quantumEnd == 0 {
+		bucket.quantumEnd = warmUpRequests + bucket.quantum
+	}
+	for numRequest >= bucket.quantumEnd {
+		remaining := bucket.allowance - bucket.used
+		if remaining > bucket.carryLimit {
+			remaining = bucket.carryLimit
+		}
+		bucket.allowance = remaining + bucket.refill
+		if bucket.allowance > bucket.burst {
+			bucket.allowance = bucket.burst
+		}
+		DFmtPrintf("TokenBucket:: request: %d, used bytes: %d, carried: %d, allowance: %d.\n",
+			numRequest, bucket.used, remaining, bucket.allowance)
+		diff := bucket.quantumAdmit - bucket.quantumSeal
+		if diff < 0 {
+			diff = -diff
+		}
+		bucket.quantumDiff += diff
+		if diff > bucket.maxQuantumDiff {
+			bucket.maxQuantumDiff = diff
+		}
+		bucket.quantumAdmit = 0
+		bucket.quantumSeal = 0
+		bucket.lastUsed = bucket.used
+		bucket.used = 0
+		bucket.quanta++
+		bucket.quantumEnd += bucket.quantum
+	}
+}
+
+/**
+	Return false if nothing is recorded, i.e. there is no budget or it is the warm up phase.
+	Only charged bytes count against the allowance.
+ */
+func (bucket *TokenBucket) record(bytes int64, charged bool) bool {
+	if bucket == nil {
+		return false
+	}
+	if numRequest < warmUpRequests {
+		if charged {
+			bucket.warmUpBytes += bytes
+		}
+		return false
+	}
+	if charged {
+		bucket.used += bytes
+		bucket.spent += bytes
+	}
+	return true
+}
+
+func (bucket *TokenBucket) charge(bytes int64) bool {
+	return bucket.record(bytes, true)
+}
+
+func (bucket *TokenBucket) chargeAdmission(bytes int64) {
+	if bucket.record(bytes, !sealCharging) {
+		bucket.admitBytes += bytes
+		bucket.quantumAdmit += bytes
+	}
+}
+
+/**
+	A box of boxSize bytes is sealed with padding unused bytes at the end.
+ */
+func (bucket *TokenBucket) chargeSeal(boxSize int64, padding int64) {
+	if bucket.record(padding, !sealCharging) {
+		bucket.paddingBytes += padding
+	}
+	if bucket.record(boxSize, sealCharging) {
+		bucket.sealBytes += boxSize
+		bucket.quantumSeal += boxSize
+	}
+}
+
+func (bucket *TokenBucket) chargeGC(bytes int64) {
+	if bucket.charge(bytes) {
+		bucket.gcBytes += bytes
+	}
+}
+
+/**
+	Used bytes and allowance of the current quantum.
+ */
+func (bucket *TokenBucket) Used() int64 {
+	return bucket.used
+}
+
+func (bucket *TokenBucket) Allowance() int64 {
+	return bucket.allowance
+}
+
+func (bucket *TokenBucket) Remaining() int64 {
+	return bucket.allowance - bucket.used
+}
+
+func (bucket *TokenBucket) Refill() int64 {
+	return bucket.refill
+}
+
+/**
+	Return bytes of admissions, seal padding and GC since the warm up phase.
+ */
+func GetWriteBudgetResults() (int64, int64, int64) {
+	if writeBudget == nil {
+		return 0, 0, 0
+	}
+	bucket := writeBudget
+	fmt.Printf("Write budget:: refill: %d, burst: %d, carry limit: %d, quanta: %d, allowance: %d, used: %d, "+
+		"seal charging: %t, admitted bytes: %d, padding bytes: %d, sealed bytes: %d, GC bytes: %d, "+
+		"warm up bytes: %d.\n", bucket.refill, bucket.burst, bucket.carryLimit, bucket.quanta, bucket.allowance,
+		bucket.used, sealCharging, bucket.admitBytes, bucket.paddingBytes, bucket.sealBytes, bucket.gcBytes,
+		bucket.warmUpBytes)
+	return bucket.admitBytes, bucket.paddingBytes, bucket.gcBytes
+}
+
+/**
+	Compare per-object charging with seal charging. Return admitted bytes, sealed bytes and the mean absolute
+	difference per quantum relative to the refill.
+	Admitted bytes higher than sealed bytes: objects still open or dropped before their box sealed.
+	Sealed bytes higher: padding at the end of boxes.
+ */
+func GetSealChargingResults() (int64, int64, float64) {
+	if writeBudget == nil {
+		return 0, 0, 0
+	}
+	bucket := writeBudget
+	meanDiff := 0.0
+	if bucket.quanta > 0 && bucket.refill > 0 {
+		meanDiff = float64(bucket.quantumDiff) / float64(bucket.quanta) / float64(bucket.refill)
+	}
+	fmt.Printf("Seal charging:: admitted bytes: %d, sealed bytes: %d, difference: %d, "+
+		"mean difference per quantum: %f of refill, max difference in a quantum: %d.\n",
+		bucket.admitBytes, bucket.sealBytes, bucket.admitBytes - bucket.sealBytes, meanDiff, bucket.maxQuantumDiff)
+	return bucket.admitBytes, bucket.sealBytes, meanDiff
+}
@@ -0,0 +1,124 @@
+package ObjectBased
+
+import "testing"
+
+/**
+	Move to request n and start the quanta which are due.
+ */
+func tickTo(bucket *TokenBucket, n int64) {
+	numRequest = n
+	bucket.tick()
+}
+
+func TestTokenBucketCarry(t *testing.T) {
+	testStartUp(t)
+	bucket := newTokenBucket(100, 100, 150, 30, 10)
+	steps := []struct {
+		request		int64
+		charge		int64
+		allowance	int64		// after the tick
+	}{
+		{0, 40, 100},
+		{10, 200, 130},		// 60 unused bytes, 30 carried over
+		{20, 0, 30},		// the debt of 70 bytes is carried over
+		{30, 0, 130},
+		{40, 0, 130},		// burst is not reached
+	}
+	for _, step := range steps {
+		tickTo(bucket, step.request)
+		if bucket.Allowance() != step.allowance || bucket.Used() != 0 {
+			t.Fatalf("request %d: allowance %d, used %d, expected %d and 0", step.request, bucket.Allowance(), bucket.Used(), step.allowance)
+		}
+		bucket.chargeAdmission(step.charge)
+	}
+	if bucket.quanta != 4 || bucket.spent != 240 || bucket.admitBytes != 240 {
+		t.Fatalf("%d quanta, %d bytes spent, %d admitted", bucket.quanta, bucket.spent, bucket.admitBytes)
+	}
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	testStartUp(t)
+	bucket := newTokenBucket(100, 100, 150, unlimited, 10)
+	tickTo(bucket, 0)
+	// three quanta pass at once without any write, the allowance stops at burst
+	tickTo(bucket, 35)
+	if bucket.quanta != 3 || bucket.Allowance() != 150 {
+		t.Fatalf("%d quanta, allowance %d, expected 3 and 150", bucket.quanta, bucket.Allowance())
+	}
+	bucket.chargeGC(120)
+	if bucket.Remaining() != 30 || bucket.gcBytes != 120 {
+		t.Fatalf("%d bytes remaining, %d GC bytes", bucket.Remaining(), bucket.gcBytes)
+	}
+}
+
+func TestTokenBucketWarmUp(t *testing.T) {
+	testStartUp(t)
+	SetWarmUp(100)
+	defer SetWarmUp(0)
+	bucket := newTokenBucket(100, 100, 100, 0, 10)
+	tickTo(bucket, 50)
+	bucket.chargeAdmission(500)
+	if bucket.Used() != 0 || bucket.admitBytes != 0 || bucket.warmUpBytes != 500 || bucket.quantumEnd != 0 {
+		t.Fatalf("charged during the warm up phase: used %d, admitted %d", bucket.Used(), bucket.admitBytes)
+	}
+	// the first quantum starts when the warm up phase ends
+	tickTo(bucket, 100)
+	bucket.chargeAdmission(60)
+	tickTo(bucket, 109)
+	if bucket.quantumEnd != 110 || bucket.Used() != 60 || bucket.quanta != 0 {
+		t.Fatalf("quantum ends at %d, used %d, %d quanta", bucket.quantumEnd, bucket.Used(), bucket.quanta)
+	}
+}
+
+func TestNoWriteBudget(t *testing.T) {
+	testStartUp(t)
+	writeBudget = nil
+	writeBudget.tick()
+	writeBudget.chargeAdmission(100)
+	if admitted, padding, gc := GetWriteBudgetResults(); admitted != 0 || padding != 0 || gc != 0 {
+		t.Fatalf("results without a budget: %d, %d, %d", admitted, padding, gc)
+	}
+}
+
+func TestSealCharging(t *testing.T) {
+	defer SetSealCharging(false)
+	for _, on := range []bool{false, true} {
+		SetSealCharging(on)
+		testStartUp(t)
+		bucket := newTokenBucket(1000, 1000, 1000, 0, 10)
+		tickTo(bucket, 0)
+		bucket.chargeAdmission(100)
+		// a box of 1000 bytes sealed with 300 bytes unused
+		bucket.chargeSeal(1000, 300)
+		expected := int64(400)
+		if on {
+			expected = 1000
+		}
+		if bucket.Used() != expected || bucket.admitBytes != 100 || bucket.paddingBytes != 300 || bucket.sealBytes != 1000 {
+			t.Fatalf("seal charging %t: used %d, expected %d", on, bucket.Used(), expected)
+		}
+		tickTo(bucket, 10)
+		if bucket.quantumDiff != 900 || bucket.quantumAdmit != 0 || bucket.quantumSeal != 0 {
+			t.Fatalf("seal charging %t: difference %d in the first quantum, expected 900", on, bucket.quantumDiff)
+		}
+	}
+}
+
+func TestSealChargingReplay(t *testing.T) {
+	defer SetSealCharging(false)
+	for _, on := range []bool{false, true} {
+		SetSealCharging(on)
+		testStartUp(t)
+		WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+		replay("lameDuck", 30000)
+		admitted, sealed, _ := GetSealChargingResults()
+		expected := admitted + writeBudget.paddingBytes
+		if on {
+			expected = sealed
+		}
+		if sealed != sealedBytes || writeBudget.spent != expected {
+			t.Fatalf("seal charging %t: %d bytes spent, expected %d, %d sealed bytes of %d", on,
+				writeBudget.spent, expected, sealed, sealedBytes)
+		}
+	}
+}
@@ -37,8 +37,8 @@ func collectStat(size string) {
 	power := toFixed(math.Log10(number), 1)
 	count[power]++
 
-	if numRequest % Epoch == 0 {
-		DynamicGranularity(len(granularity))
+	if dynamicGranularity && numRequest % Epoch == 0 {
+		DynamicGranularity(granularityClasses)
 	}
 }
 
@@ -77,21 +77,9 @@ func DynamicGranularity(number int) {
 		tempGran = append(tempGran, int64(math.Pow(10, getIntervals(base * i, counts, intervals))))
 	}
 	tempGran = append(tempGran, maxObjSize)
-	updateUpperBound(tempGran)
+	updateGranularity(tempGran)
 
 	//DFmtPrintf("DynamicGranularity:: Request: %d. Current granularity is: %v.\n", numRequest, tempGran)
-	granularity = tempGran
-}
-
-func updateUpperBound(tempGran []int64) {
-	newOpenBoxes := make(map[int64]*Box)
-	for index, value := range granularity {
-		box := openBoxes[value]
-		box.upperBound = tempGran[index]
-		newOpenBoxes[tempGran[index]] = box
-	}
-	openBoxes = newOpenBoxes
-	//fmt.Println("Open boxes are updated. ", openBoxes)
 }
 
 /**
@@ -140,3 +128,199 @@ func getIntervals(number int, counts []int, intervals []float64) float64 {
 	}
 	return 0;
 }
+
+/**
+	Size classes in use from the given request on.
+ */
+type GranularityChange struct {
+	Request		int64
+	Bounds		[]int64
+}
+
+/**
+	Size classes which still have sealed boxes in flash. An entry is dropped when its last sealed box is
+	evicted, unless it is the current one.
+ */
+type liveClasses struct {
+	bounds		[]int64
+	generation	int64
+	liveBoxes	int64		// sealed boxes of these size classes still in flash
+}
+
+type packedObject struct {
+	id			string
+	size		int64
+}
+
+/**
+	Sorted upper bounds without duplicates. Quantiles of the size histogram may collide, which merges classes.
+ */
+func uniqueBounds(bounds []int64) []int64 {
+	sorted := append([]int64(nil), bounds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	result := make([]int64, 0, len(sorted))
+	for _, bound := range sorted {
+		if bound <= 0 || (len(result) > 0 && result[len(result) - 1] == bound) {
+			continue
+		}
+		result = append(result, bound)
+	}
+	return result
+}
+
+func sameBounds(a []int64, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if a[index] != b[index] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+	Switch to new size classes. Classes may be merged or split, so every open box is replaced by a new one
+	and its objects are packed again into the open boxes of their new classes. Sealed boxes keep their old
+	upper bounds until they are evicted.
+ */
+func updateGranularity(bounds []int64) {
+	bounds = uniqueBounds(bounds)
+	if len(bounds) == 0 || sameBounds(bounds, granularity) {
+		return
+	}
+	oldGranularity := granularity
+	oldBoxes := openBoxes
+	updateBoxSizes(oldGranularity, bounds)
+	granularity = bounds
+	openBoxes = make(map[int64]*Box, len(bounds))
+	for _, bound := range bounds {
+		newOpenBox(bound)
+	}
+	// boxes sealed while packing already belong to the new size classes
+	granularityGeneration++
+	GranularityHistory = append(GranularityHistory, GranularityChange{Request: numRequest, Bounds: bounds})
+	liveGranularity = append(liveGranularity, liveClasses{bounds: bounds, generation: granularityGeneration})
+	pruneLiveGranularity()
+	// boxes sealed while packing go into the partitions of the new classes
+	if boxEviction == "PARTITION" {
+		partitionCarryShares(oldGranularity, bounds)
+	}
+
+	for _, oldBound := range oldGranularity {
+		box := oldBoxes[oldBound]
+		openBytes -= box.currSize
+		for _, object := range boxObjects(box) {
+			bound := getBound(object.size)
+			if bound == -1 || object.size > openBoxes[bound].maxSize {
+				DPrintf("updateGranularity:: object %s with size %d does not fit any new class.\n",
+					object.id, object.size)
+				continue
+			}
+			if _, ok := openBoxes[bound].objOffsetMap[object.id]; ok {
+				continue
+			}
+			addToOpenBox(openBoxes[bound], object.size, bound, object.id)
+			// keep the age of the oldest object
+			target := openBoxes[bound]
+			if box.openedAt < target.openedAt {
+				target.openedAt = box.openedAt
+				target.openedTime = box.openedTime
+			}
+		}
+	}
+	DFmtPrintf("updateGranularity:: request: %d, granularity: %v -> %v.\n", numRequest, oldGranularity, bounds)
+}
+
+/**
+	Objects of a box in write order. Sizes are the gaps between offsets.
+ */
+func boxObjects(box *Box) []packedObject {
+	ids := make([]string, 0, len(box.objOffsetMap))
+	for id := range box.objOffsetMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return box.objOffsetMap[ids[i]] < box.objOffsetMap[ids[j]] })
+
+	objects := make([]packedObject, len(ids))
+	for index, id := range ids {
+		end := box.currSize
+		if index + 1 < len(ids) {
+			end = box.objOffsetMap[ids[index + 1]]
+		}
+		objects[index] = packedObject{id: id, size: end - box.objOffsetMap[id]}
+	}
+	return objects
+}
+
+/**
+	Box sizes of new size classes: a new class takes the box size of the old class its upper bound belonged to.
+ */
+func updateBoxSizes(oldBounds []int64, bounds []int64) {
+	sizes := make(map[int64]int64, len(bounds))
+	for _, bound := range bounds {
+		for _, oldBound := range oldBounds {
+			if oldBound < bound {
+				continue
+			}
+			if size, ok := boundBoxSizes[oldBound]; ok {
+				sizes[bound] = size
+			}
+			break
+		}
+	}
+	boundBoxSizes = sizes
+}
+
+func granularitySeal(box *Box) {
+	box.generation = granularityGeneration
+	liveGranularity[len(liveGranularity) - 1].liveBoxes++
+}
+
+func granularityEvict(box *Box) {
+	for index := range liveGranularity {
+		if liveGranularity[index].generation == box.generation {
+			liveGranularity[index].liveBoxes--
+			break
+		}
+	}
+	pruneLiveGranularity()
+}
+
+/**
+	Drop old size classes without sealed boxes, they are never probed again.
+ */
+func pruneLiveGranularity() {
+	last := len(liveGranularity) - 1
+	live := liveGranularity[:0]
+	for index, classes := range liveGranularity {
+		if classes.liveBoxes > 0 || index == last {
+			live = append(live, classes)
+		}
+	}
+	liveGranularity = live
+}
+
+/**
+	Upper bounds of every live class the size has belonged to, newest first.
+ */
+func historicalBounds(size int64) []int64 {
+	result := make([]int64, 0, 1)
+	for index := len(liveGranularity) - 1; index >= 0; index-- {
+		for _, bound := range liveGranularity[index].bounds {
+			if bound < size {
+				continue
+			}
+			seen := false
+			for _, prev := range result {
+				seen = seen || prev == bound
+			}
+			if !seen {
+				result = append(result, bound)
+			}
+			break
+		}
+	}
+	return result
+}
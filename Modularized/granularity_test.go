@@ -0,0 +1,85 @@
+package ObjectBased
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+/**
+	Replay the test trace and switch size classes twice. Return the hits.
+ */
+func replayRegranularized(t *testing.T, mode string) int64 {
+	SetLookupMode(mode)
+	defer SetLookupMode("map")
+	testStartUp(t)
+	WriteBudgetSetUp(unlimited, unlimited, 0, Epoch)
+	random := rand.New(rand.NewSource(7))
+	zipf := rand.NewZipf(random, 1.1, 1, testObjects - 1)
+	for index := 1; index <= 30000; index++ {
+		id := int(zipf.Uint64())
+		Request(strconv.Itoa(id), strconv.FormatInt(testSize(id), 10), "lameDuck")
+		switch index {
+		case 10000:
+			updateGranularity([]int64{1000, 1000, testMaxObjSize})
+		case 20000:
+			updateGranularity([]int64{500, 20000, 200000, testMaxObjSize})
+		}
+	}
+	var open int64
+	for _, bound := range granularity {
+		open += openBoxes[bound].currSize
+	}
+	if open != openBytes {
+		t.Fatalf("%s: open boxes hold %d bytes, openBytes is %d", mode, open, openBytes)
+	}
+	var live int64
+	for _, classes := range liveGranularity {
+		live += classes.liveBoxes
+	}
+	if live != int64(len(boxQueueMap)) {
+		t.Fatalf("%s: history counts %d sealed boxes, %d in flash", mode, live, len(boxQueueMap))
+	}
+	return hits
+}
+
+func TestRegranularizationLookup(t *testing.T) {
+	mapHits := replayRegranularized(t, "map")
+	bloomHits := replayRegranularized(t, "bloom")
+	if mapHits == 0 || mapHits != bloomHits {
+		t.Fatalf("hits with map lookup: %d, with bloom lookup: %d", mapHits, bloomHits)
+	}
+}
+
+func TestBoxSizesFollowBounds(t *testing.T) {
+	defer SetClassBoxSizes(nil)
+	SetClassBoxSizes([]int64{1 << 16, 1 << 17, 1 << 18, 1 << 19})
+	StartUpWithGranularity(64 << 20, []int64{1024, 32768, 262144, testMaxObjSize}, testMaxObjSize, 4 << 20)
+	updateGranularity([]int64{512, 1024, 32768, testMaxObjSize})
+	expected := map[int64]int64{512: 1 << 16, 1024: 1 << 16, 32768: 1 << 17, testMaxObjSize: 1 << 19}
+	for bound, size := range expected {
+		if openBoxes[bound].maxSize != size {
+			t.Fatalf("class %d has boxes of %d bytes, expected %d", bound, openBoxes[bound].maxSize, size)
+		}
+	}
+}
+
+func TestGranularityHistoryPruned(t *testing.T) {
+	testStartUp(t)
+	box := &Box{upperBound: granularity[0]}
+	granularitySeal(box)
+	updateGranularity([]int64{1000, 20000, testMaxObjSize})
+	updateGranularity([]int64{2000, testMaxObjSize})
+	if len(liveGranularity) != 2 {
+		t.Fatalf("%d live size classes, only the first one has a sealed box", len(liveGranularity))
+	}
+	granularityEvict(box)
+	if len(liveGranularity) != 1 || !sameBounds(liveGranularity[0].bounds, granularity) {
+		t.Fatalf("live size classes after the last old box is evicted: %v", liveGranularity)
+	}
+	// the exported history keeps every change
+	if len(GranularityHistory) != 3 || GranularityHistory[2].Request != numRequest ||
+		!sameBounds(GranularityHistory[1].Bounds, []int64{1000, 20000, testMaxObjSize}) {
+		t.Fatalf("history of the size classes: %v", GranularityHistory)
+	}
+}